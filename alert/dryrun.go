@@ -0,0 +1,31 @@
+package alert
+
+import "sync"
+
+// When dry running, alerts are captured instead of sent or throttled
+var dryRun bool
+var captured []Record
+var capturedLock sync.Mutex
+
+// SetDryRun to capture alerts instead of sending them
+func SetDryRun(enabled bool) {
+	dryRun = enabled
+}
+
+// Captured alerts that would have been sent while dry running, oldest first
+func Captured() []Record {
+	capturedLock.Lock()
+	defer capturedLock.Unlock()
+	return append([]Record{}, captured...)
+}
+
+// capture an alert if dry running, returning true if it was captured
+func capture(notifier string, text string) bool {
+	if !dryRun {
+		return false
+	}
+	capturedLock.Lock()
+	defer capturedLock.Unlock()
+	captured = append(captured, newRecord(notifier, text))
+	return true
+}
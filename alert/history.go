@@ -0,0 +1,50 @@
+package alert
+
+import (
+	"sync"
+	"time"
+)
+
+// How many sent alerts to remember
+const historySize = 100
+
+// Record of an alert that was sent
+type Record struct {
+	Time     time.Time `json:"time"`
+	Notifier string    `json:"notifier"`
+	Text     string    `json:"text"`
+}
+
+var history []Record
+var historyLock sync.Mutex
+
+// remember that an alert was sent through this notifier
+func record(notifier string, text string) {
+	historyLock.Lock()
+	defer historyLock.Unlock()
+
+	history = append(history, newRecord(notifier, text))
+	if len(history) > historySize {
+		history = history[len(history)-historySize:]
+	}
+}
+
+func newRecord(notifier string, text string) Record {
+	return Record{
+		Time:     time.Now(),
+		Notifier: notifier,
+		Text:     text,
+	}
+}
+
+// Recent alerts that were sent, newest first
+func Recent() []Record {
+	historyLock.Lock()
+	defer historyLock.Unlock()
+
+	recent := make([]Record, len(history))
+	for i, r := range history {
+		recent[len(history)-1-i] = r
+	}
+	return recent
+}
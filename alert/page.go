@@ -2,16 +2,19 @@ package alert
 
 import (
 	"fmt"
-	"log"
 	"os"
 	"time"
 
 	"github.com/PagerDuty/go-pagerduty"
 	"github.com/nlopes/slack"
+	"gitlab.com/polychainlabs/tezos-network-monitor/metrics"
 )
 
 // Page your team
 func Page(title string, body string) {
+	if capture("pagerduty", title) {
+		return
+	}
 	if hasAlreadyAlerted(title, 20*time.Minute) {
 		return
 	}
@@ -38,7 +41,9 @@ func Page(title string, body string) {
 		},
 	})
 	if err != nil {
-		log.Println("Erorr creating incident.  Reason:")
-		log.Println(err.Error())
+		logger.WithError(err).WithField("title", title).Error("Error creating incident")
+		return
 	}
+	metrics.AlertsSent.WithLabelValues("pagerduty").Inc()
+	record("pagerduty", title)
 }
@@ -1,28 +1,36 @@
 package alert
 
 import (
-	"log"
 	"os"
 	"time"
 
 	"github.com/nlopes/slack"
+	"gitlab.com/polychainlabs/tezos-network-monitor/logging"
+	"gitlab.com/polychainlabs/tezos-network-monitor/metrics"
 )
 
+var logger = logging.Component("alert")
+
 // PostSlack message
 func PostSlack(msg *slack.WebhookMessage) {
 	// Set Defaults
 	if len(msg.Channel) == 0 {
 		msg.Channel = os.Getenv("SLACK_CHANNEL")
 	}
+	if capture("slack", msg.Text) {
+		return
+	}
 	// Throttle
 	if hasAlreadyAlerted(msg.Text+msg.Channel, 10*time.Minute) {
-		log.Println("Would have sent alert text: ", msg.Text)
-		log.Println("Has already alerted.  Not posting again.")
+		logger.WithField("text", msg.Text).Info("Has already alerted.  Not posting again.")
 		return
 	}
 	// Post
 	err := slack.PostWebhook(os.Getenv("SLACK_URL"), msg)
 	if err != nil {
-		log.Println("Error posting slack webhook: ", err)
+		logger.WithError(err).WithField("text", msg.Text).Error("Error posting slack webhook")
+		return
 	}
+	metrics.AlertsSent.WithLabelValues("slack").Inc()
+	record("slack", msg.Text)
 }
@@ -1,22 +1,60 @@
 package alert
 
-import "time"
+import (
+	"sort"
+	"sync"
+	"time"
+)
 
 // Throttle Alerts
-var alertThrottle map[string]*time.Time
+var alertThrottle map[string]throttle
+var alertThrottleLock sync.Mutex
+
+type throttle struct {
+	sent     time.Time
+	duration time.Duration
+}
+
+// Silence on a message that has already been alerted and won't be sent again
+// until `Until`
+type Silence struct {
+	Message string    `json:"message"`
+	Until   time.Time `json:"until"`
+}
 
 // return true if an alert has already been sent with this message in the
 // provided duration
 func hasAlreadyAlerted(message string, duration time.Duration) bool {
+	alertThrottleLock.Lock()
+	defer alertThrottleLock.Unlock()
 	if alertThrottle == nil {
-		alertThrottle = map[string]*time.Time{}
+		alertThrottle = map[string]throttle{}
 	}
 
-	lastAlert, _ := alertThrottle[message]
+	lastAlert, ok := alertThrottle[message]
 	now := time.Now()
-	if lastAlert == nil || now.Sub(*lastAlert) > duration {
-		alertThrottle[message] = &now
+	if !ok || now.Sub(lastAlert.sent) > duration {
+		alertThrottle[message] = throttle{sent: now, duration: duration}
 		return false
 	}
 	return true
 }
+
+// Silences currently active, soonest to expire first
+func Silences() []Silence {
+	alertThrottleLock.Lock()
+	defer alertThrottleLock.Unlock()
+
+	silences := []Silence{}
+	now := time.Now()
+	for message, t := range alertThrottle {
+		until := t.sent.Add(t.duration)
+		if until.After(now) {
+			silences = append(silences, Silence{Message: message, Until: until})
+		}
+	}
+	sort.Slice(silences, func(i, j int) bool {
+		return silences[i].Until.Before(silences[j].Until)
+	})
+	return silences
+}
@@ -0,0 +1,25 @@
+package alert
+
+import (
+	"testing"
+	"time"
+)
+
+func TestThrottle(t *testing.T) {
+	if hasAlreadyAlerted("test throttle", time.Minute) {
+		t.Error("First alert should not be throttled")
+	}
+	if !hasAlreadyAlerted("test throttle", time.Minute) {
+		t.Error("Second alert should be throttled")
+	}
+
+	found := false
+	for _, s := range Silences() {
+		if s.Message == "test throttle" {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("Expected an active silence for the throttled alert")
+	}
+}
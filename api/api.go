@@ -0,0 +1,528 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"gitlab.com/polychainlabs/tezos-network-monitor/alert"
+	"gitlab.com/polychainlabs/tezos-network-monitor/logging"
+	"gitlab.com/polychainlabs/tezos-network-monitor/storage"
+	"gitlab.com/polychainlabs/tezos-network-monitor/tzrpc"
+)
+
+var logger = logging.Component("api")
+
+// Node is considered caught up while lagging less than this
+const maxReadyLag = 5 * time.Minute
+
+// Levels returned for heatmaps by default, and at most
+const defaultLevels = 100
+const maxLevels = 4096
+
+// Slashing events returned at most
+const maxSlashings = 50
+
+// Server exposing what the monitor currently thinks of the network
+type Server struct {
+	bakers  []string
+	aliases map[string]string
+}
+
+// DelegateStatus as recorded in storage
+type DelegateStatus struct {
+	Delegate               string         `json:"delegate"`
+	Alias                  string         `json:"alias"`
+	LastBakingLevel        int64          `json:"last_baking_level"`
+	LastEndorsementLevel   int64          `json:"last_endorsement_level"`
+	BakingCycle            int64          `json:"baking_cycle"`
+	BakingCycleMisses      int64          `json:"baking_cycle_misses"`
+	EndorsementCycle       int64          `json:"endorsement_cycle"`
+	EndorsementCycleMisses int64          `json:"endorsement_cycle_misses"`
+	CycleRecord            Record         `json:"cycle_record"`
+	Capacity               *Capacity      `json:"capacity,omitempty"`
+	ConsensusKeys          *ConsensusKeys `json:"consensus_keys,omitempty"`
+}
+
+// ConsensusKeys a delegate signs with, as last checked
+type ConsensusKeys struct {
+	Active  string       `json:"active"`
+	Pending []PendingKey `json:"pending"`
+}
+
+// PendingKey activating at the start of a cycle
+type PendingKey struct {
+	Cycle int64  `json:"cycle"`
+	PKH   string `json:"pkh"`
+}
+
+// Capacity of a delegate to accept delegations in mutez, as last checked
+type Capacity struct {
+	Level          int64   `json:"level"`
+	Balance        int64   `json:"balance"`
+	FrozenBalance  int64   `json:"frozen_balance"`
+	StakingBalance int64   `json:"staking_balance"`
+	Ratio          float64 `json:"ratio"`
+	Capacity       int64   `json:"capacity"`
+	Headroom       int64   `json:"headroom"`
+	Utilization    float64 `json:"utilization"`
+	State          string  `json:"state"`
+}
+
+// Record of baking and endorsing over a cycle
+type Record struct {
+	BakingRights            int64 `json:"baking_rights"`
+	Baked                   int64 `json:"baked"`
+	Missed                  int64 `json:"missed"`
+	Stolen                  int64 `json:"stolen"`
+	EndorsementSlots        int64 `json:"endorsement_slots"`
+	EndorsementSlotsMissed  int64 `json:"endorsement_slots_missed"`
+	EndorsementLevelsMissed int64 `json:"endorsement_levels_missed"`
+}
+
+// Level performance of a delegate, for heatmaps
+type Level struct {
+	Level  int64  `json:"level"`
+	Baking string `json:"baking,omitempty"`
+	// Baker and priority of the block when we had rights, and who we stole
+	// it from
+	BakedBy            string   `json:"baked_by,omitempty"`
+	BakedAtPriority    *int64   `json:"baked_at_priority,omitempty"`
+	StolenFrom         []string `json:"stolen_from,omitempty"`
+	EndorsementSlots   int64    `json:"endorsement_slots"`
+	EndorsementsMissed int64    `json:"endorsements_missed"`
+	// Block the endorsement was included in, its baker, and why it was
+	// missed
+	EndorsementIncludedLevel int64  `json:"endorsement_included_level,omitempty"`
+	EndorsementIncludedBy    string `json:"endorsement_included_by,omitempty"`
+	EndorsementMissReason    string `json:"endorsement_miss_reason,omitempty"`
+}
+
+// Rewards of a delegate over a cycle in mutez, against what its rights could
+// have earned
+type Rewards struct {
+	Cycle             int64            `json:"cycle"`
+	ExpectedBaking    int64            `json:"expected_baking"`
+	EarnedBaking      int64            `json:"earned_baking"`
+	ExpectedEndorsing int64            `json:"expected_endorsing"`
+	EarnedEndorsing   int64            `json:"earned_endorsing"`
+	Fees              int64            `json:"fees"`
+	Deposits          int64            `json:"deposits"`
+	Slashed           int64            `json:"slashed"`
+	Lost              int64            `json:"lost"`
+	Categories        map[string]int64 `json:"categories"`
+}
+
+// CycleForecast of a delegate's rights and deposits for an upcoming cycle, or
+// what's left of the current one, in mutez
+type CycleForecast struct {
+	Cycle            int64 `json:"cycle"`
+	Blocks           int64 `json:"blocks"`
+	EndorsementSlots int64 `json:"endorsement_slots"`
+	Deposits         int64 `json:"deposits"`
+	Available        int64 `json:"available"`
+}
+
+// Balance of an address at a level, in tez
+type Balance struct {
+	Level     int64 `json:"level"`
+	Cycle     int64 `json:"cycle"`
+	Balance   int64 `json:"balance"`
+	Spendable int64 `json:"spendable"`
+}
+
+// Node status as seen right now
+type Node struct {
+	Block      string    `json:"block"`
+	Timestamp  time.Time `json:"timestamp"`
+	LagSeconds float64   `json:"lag_seconds"`
+}
+
+// Slashing event found on chain
+type Slashing struct {
+	Kind     string `json:"kind"`
+	Offender string `json:"offender"`
+	Alias    string `json:"alias"`
+	Level    int64  `json:"level"`
+	Cycle    int64  `json:"cycle"`
+	Amount   int64  `json:"amount"`
+	Ours     bool   `json:"ours"`
+}
+
+// Protocol that validated a range of processed levels
+type Protocol struct {
+	Protocol   string `json:"protocol"`
+	FirstLevel int64  `json:"first_level"`
+	LastLevel  int64  `json:"last_level"`
+	Supported  bool   `json:"supported"`
+}
+
+// Status of the whole monitor
+type Status struct {
+	LastBlockLevel int64            `json:"last_block_level"`
+	Delegates      []DelegateStatus `json:"delegates"`
+	Alerts         []alert.Record   `json:"alerts"`
+	Silences       []alert.Silence  `json:"silences"`
+}
+
+// New status server for these bakers
+func New(bakers []string, aliases map[string]string) *Server {
+	return &Server{
+		bakers:  bakers,
+		aliases: aliases,
+	}
+}
+
+// Handler serving every route, including metrics
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	mux.HandleFunc("/", s.dashboard)
+	mux.HandleFunc("/healthz", s.healthz)
+	mux.HandleFunc("/readyz", s.readyz)
+	mux.HandleFunc("/api/status", s.status)
+	mux.HandleFunc("/api/delegates", s.delegates)
+	mux.HandleFunc("/api/delegates/", s.delegate)
+	mux.HandleFunc("/api/balances/", s.balances)
+	mux.HandleFunc("/api/alerts", s.alerts)
+	mux.HandleFunc("/api/silences", s.silences)
+	mux.HandleFunc("/api/slashings", s.slashings)
+	mux.HandleFunc("/api/node", s.node)
+	mux.HandleFunc("/api/protocols", s.protocols)
+	return mux
+}
+
+// healthz responds as long as the process is alive
+func (s *Server) healthz(w http.ResponseWriter, r *http.Request) {
+	w.Write([]byte("ok\n"))
+}
+
+// readyz responds successfully only if the node is reachable and caught up
+func (s *Server) readyz(w http.ResponseWriter, r *http.Request) {
+	bootstrapped, err := tzrpc.GetBootstrapped()
+	if err != nil {
+		http.Error(w, "node unreachable: "+err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	lag := time.Duration(bootstrapped.Lag) * time.Second
+	if lag > maxReadyLag {
+		http.Error(w, "node lagging by "+lag.String(), http.StatusServiceUnavailable)
+		return
+	}
+	w.Write([]byte("ok\n"))
+}
+
+func (s *Server) status(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, Status{
+		LastBlockLevel: storage.GetLastRecordedBlockLevel(),
+		Delegates:      s.delegateStatuses(),
+		Alerts:         alert.Recent(),
+		Silences:       alert.Silences(),
+	})
+}
+
+func (s *Server) delegates(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, s.delegateStatuses())
+}
+
+// delegate status at /api/delegates/<pkh>, its recent levels at
+// /api/delegates/<pkh>/levels?count=N, its rewards at
+// /api/delegates/<pkh>/rewards?cycle=N, or its upcoming rights at
+// /api/delegates/<pkh>/forecast
+func (s *Server) delegate(w http.ResponseWriter, r *http.Request) {
+	path := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/delegates/"), "/")
+	delegate := path[0]
+	if !s.isBaker(delegate) || len(path) > 2 {
+		http.NotFound(w, r)
+		return
+	}
+	if len(path) == 1 {
+		writeJSON(w, s.delegateStatus(delegate))
+		return
+	}
+	if path[1] == "rewards" {
+		s.rewards(w, r, delegate)
+		return
+	}
+	if path[1] == "forecast" {
+		s.forecast(w, r, delegate)
+		return
+	}
+	if path[1] != "levels" {
+		http.NotFound(w, r)
+		return
+	}
+
+	count := defaultLevels
+	if c, err := strconv.Atoi(r.URL.Query().Get("count")); err == nil && c > 0 && c <= maxLevels {
+		count = c
+	}
+	writeJSON(w, delegateLevels(delegate, count))
+}
+
+// rewards of a delegate in the requested cycle, defaulting to its latest
+// baking cycle
+func (s *Server) rewards(w http.ResponseWriter, r *http.Request, delegate string) {
+	cycle := storage.GetLatestBakingCycle(delegate)
+	if c, err := strconv.ParseInt(r.URL.Query().Get("cycle"), 10, 64); err == nil && c >= 0 {
+		cycle = c
+	}
+	_, last := tzrpc.CycleLevels(cycle)
+	constants, err := tzrpc.GetRewardConstants(strconv.FormatInt(last, 10), cycle)
+	if err != nil {
+		constants, err = tzrpc.GetRewardConstants("head", cycle)
+	}
+	if err != nil {
+		http.Error(w, "node unreachable: "+err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	c := storage.CompareRewards(delegate, cycle, constants)
+	writeJSON(w, Rewards{
+		Cycle:             c.Cycle,
+		ExpectedBaking:    c.ExpectedBaking,
+		EarnedBaking:      c.EarnedBaking,
+		ExpectedEndorsing: c.ExpectedEndorsing,
+		EarnedEndorsing:   c.EarnedEndorsing,
+		Fees:              c.Fees,
+		Deposits:          c.Deposits,
+		Slashed:           c.Slashed,
+		Lost:              c.Lost,
+		Categories:        c.Categories,
+	})
+}
+
+// forecast of a delegate's upcoming cycles, as last checked
+func (s *Server) forecast(w http.ResponseWriter, r *http.Request, delegate string) {
+	f, ok := storage.GetForecast(delegate)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	cycles := []CycleForecast{}
+	for _, c := range f.Cycles {
+		cycles = append(cycles, CycleForecast{
+			Cycle:            c.Cycle,
+			Blocks:           c.Blocks,
+			EndorsementSlots: c.EndorsementSlots,
+			Deposits:         c.Deposits,
+			Available:        c.Available,
+		})
+	}
+	writeJSON(w, cycles)
+}
+
+// balances of an address at /api/balances/<address>?count=N, oldest first
+func (s *Server) balances(w http.ResponseWriter, r *http.Request) {
+	address := strings.TrimPrefix(r.URL.Path, "/api/balances/")
+	count := defaultLevels
+	if c, err := strconv.Atoi(r.URL.Query().Get("count")); err == nil && c > 0 && c <= maxLevels {
+		count = c
+	}
+	snapshots := storage.GetBalances(address, count)
+	if len(snapshots) == 0 {
+		http.NotFound(w, r)
+		return
+	}
+
+	balances := []Balance{}
+	for _, b := range snapshots {
+		balances = append(balances, Balance{Level: b.Level, Cycle: b.Cycle, Balance: b.Balance, Spendable: b.Spendable})
+	}
+	writeJSON(w, balances)
+}
+
+func (s *Server) alerts(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, alert.Recent())
+}
+
+func (s *Server) silences(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, alert.Silences())
+}
+
+func (s *Server) slashings(w http.ResponseWriter, r *http.Request) {
+	slashings := []Slashing{}
+	recent := storage.GetSlashings(maxSlashings)
+	for i := len(recent) - 1; i >= 0; i-- {
+		slashings = append(slashings, Slashing{
+			Kind:     recent[i].Kind,
+			Offender: recent[i].Offender,
+			Alias:    alert.Alias(s.aliases, recent[i].Offender),
+			Level:    recent[i].Level,
+			Cycle:    recent[i].Cycle,
+			Amount:   recent[i].Amount,
+			Ours:     recent[i].Ours,
+		})
+	}
+	writeJSON(w, slashings)
+}
+
+func (s *Server) protocols(w http.ResponseWriter, r *http.Request) {
+	protocols := []Protocol{}
+	for _, p := range storage.GetProtocols() {
+		protocols = append(protocols, Protocol{
+			Protocol:   p.Protocol,
+			FirstLevel: p.FirstLevel,
+			LastLevel:  p.LastLevel,
+			Supported:  p.Supported,
+		})
+	}
+	writeJSON(w, protocols)
+}
+
+func (s *Server) node(w http.ResponseWriter, r *http.Request) {
+	bootstrapped, err := tzrpc.GetBootstrapped()
+	if err != nil {
+		http.Error(w, "node unreachable: "+err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	writeJSON(w, Node{
+		Block:      bootstrapped.Block,
+		Timestamp:  bootstrapped.Timestamp,
+		LagSeconds: bootstrapped.Lag,
+	})
+}
+
+func (s *Server) delegateStatuses() []DelegateStatus {
+	statuses := []DelegateStatus{}
+	for _, baker := range s.bakers {
+		statuses = append(statuses, s.delegateStatus(baker))
+	}
+	return statuses
+}
+
+func (s *Server) delegateStatus(delegate string) DelegateStatus {
+	bakingMisses, bakingCycle := storage.GetCycleBakeMissCount(delegate)
+	status := DelegateStatus{
+		Delegate:               delegate,
+		Alias:                  alert.Alias(s.aliases, delegate),
+		LastBakingLevel:        storage.GetLastRecordedBakeLevel(delegate),
+		LastEndorsementLevel:   storage.GetLastRecordedEndorsementLevel(delegate),
+		BakingCycle:            bakingCycle,
+		BakingCycleMisses:      bakingMisses,
+		EndorsementCycle:       storage.GetLatestEndorsementCycle(delegate),
+		EndorsementCycleMisses: storage.GetCycleEndorsementMissCount(delegate),
+		CycleRecord:            cycleRecord(delegate, bakingCycle, storage.GetLatestEndorsementCycle(delegate)),
+	}
+	if c, ok := storage.GetCapacity(delegate); ok {
+		status.Capacity = &Capacity{
+			Level:          c.Level,
+			Balance:        c.Balance,
+			FrozenBalance:  c.FrozenBalance,
+			StakingBalance: c.StakingBalance,
+			Ratio:          c.Ratio,
+			Capacity:       c.Capacity,
+			Headroom:       c.Headroom,
+			Utilization:    c.Utilization,
+			State:          c.State,
+		}
+	}
+	if k, ok := storage.GetConsensusKeys(delegate); ok {
+		status.ConsensusKeys = &ConsensusKeys{Active: k.Active, Pending: []PendingKey{}}
+		for _, p := range k.Pending {
+			status.ConsensusKeys.Pending = append(status.ConsensusKeys.Pending, PendingKey{Cycle: p.Cycle, PKH: p.PKH})
+		}
+	}
+	return status
+}
+
+// cycleRecord summarizing baking in `bakingCycle` and endorsing in
+// `endorsementCycle`
+func cycleRecord(delegate string, bakingCycle int64, endorsementCycle int64) Record {
+	record := Record{}
+	for _, b := range storage.GetCycleBakings(delegate, bakingCycle) {
+		if b.DelegateRights >= 0 {
+			record.BakingRights++
+		}
+		if b.DelegateBaked {
+			record.Baked++
+		}
+		if b.DelegateMissed {
+			record.Missed++
+		}
+		if b.DelegateStole {
+			record.Stolen++
+		}
+	}
+	for _, e := range storage.GetCycleEndorsements(delegate, endorsementCycle) {
+		record.EndorsementSlots += int64(len(e.Rights))
+		record.EndorsementSlotsMissed += e.Misses
+		if e.Misses > 0 {
+			record.EndorsementLevelsMissed++
+		}
+	}
+	return record
+}
+
+// delegateLevels merging the `count` most recent baking and endorsing records
+// by level, oldest first
+func delegateLevels(delegate string, count int) []Level {
+	byLevel := map[int64]*Level{}
+	for _, b := range storage.GetBakings(delegate, count) {
+		l := &Level{Level: b.Level}
+		switch {
+		case b.DelegateMissed:
+			l.Baking = "missed"
+		case b.DelegateStole:
+			l.Baking = "stolen"
+		case b.DelegateBaked:
+			l.Baking = "baked"
+		}
+		if b.DelegateRights >= 0 {
+			priority := b.BakerPriority
+			l.BakedBy = b.Baker
+			l.BakedAtPriority = &priority
+			l.StolenFrom = b.StolenFrom
+		}
+		byLevel[b.Level] = l
+	}
+	for _, e := range storage.GetEndorsements(delegate, count) {
+		l, ok := byLevel[e.Level]
+		if !ok {
+			l = &Level{Level: e.Level}
+			byLevel[e.Level] = l
+		}
+		l.EndorsementSlots = int64(len(e.Rights))
+		l.EndorsementsMissed = e.Misses
+		if i, ok := storage.GetInclusion(delegate, e.Level); ok {
+			l.EndorsementIncludedLevel = i.IncludedLevel
+			l.EndorsementIncludedBy = i.IncludedBy
+		}
+		if e.Misses > 0 {
+			l.EndorsementMissReason = storage.MissReason(delegate, e.Level)
+		}
+	}
+
+	levels := []Level{}
+	for _, l := range byLevel {
+		levels = append(levels, *l)
+	}
+	sort.Slice(levels, func(i, j int) bool {
+		return levels[i].Level < levels[j].Level
+	})
+	if len(levels) > count {
+		levels = levels[len(levels)-count:]
+	}
+	return levels
+}
+
+func (s *Server) isBaker(delegate string) bool {
+	for _, baker := range s.bakers {
+		if baker == delegate {
+			return true
+		}
+	}
+	return false
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	err := json.NewEncoder(w).Encode(v)
+	if err != nil {
+		logger.WithError(err).Error("Unable to encode response")
+	}
+}
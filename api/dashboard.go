@@ -0,0 +1,165 @@
+package api
+
+import "net/http"
+
+// dashboard served at the root, reading everything from the status API
+func (s *Server) dashboard(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write([]byte(dashboardHTML))
+}
+
+// Kept inline so the binary needs no static files or build step
+const dashboardHTML = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Tezos Network Monitor</title>
+<style>
+  body { font-family: sans-serif; margin: 2em; color: #222; }
+  h1 { font-size: 1.4em; }
+  h2 { font-size: 1.1em; margin-top: 2em; }
+  table { border-collapse: collapse; margin-bottom: 1em; }
+  th, td { padding: 4px 10px; border-bottom: 1px solid #ddd; text-align: right; }
+  th:first-child, td:first-child { text-align: left; }
+  .bad { color: #c0392b; font-weight: bold; }
+  .heatmap { display: flex; flex-wrap: wrap; max-width: 900px; }
+  .cell { width: 8px; height: 8px; margin: 1px; background: #eee; }
+  .cell.endorsed { background: #7fc97f; }
+  .cell.baked { background: #1b7837; }
+  .cell.stolen { background: #386cb0; }
+  .cell.partial { background: #fdc086; }
+  .cell.missed { background: #d7191c; }
+  .legend span { display: inline-block; margin-right: 1em; }
+  .legend .cell { display: inline-block; vertical-align: middle; }
+</style>
+</head>
+<body>
+<h1>Tezos Network Monitor</h1>
+<div id="node">Loading node status...</div>
+
+<h2>Bakers</h2>
+<table id="bakers">
+  <thead><tr>
+    <th>Baker</th><th>Cycle</th><th>Rights</th><th>Baked</th><th>Stolen</th><th>Missed</th>
+    <th>Endorsement slots</th><th>Slots missed</th><th>Last level</th>
+  </tr></thead>
+  <tbody></tbody>
+</table>
+
+<h2>Last <input id="count" type="number" value="100" min="1" max="4096" style="width:5em"> levels</h2>
+<div class="legend">
+  <span><span class="cell baked"></span> baked</span>
+  <span><span class="cell stolen"></span> stolen</span>
+  <span><span class="cell endorsed"></span> endorsed</span>
+  <span><span class="cell partial"></span> partially endorsed</span>
+  <span><span class="cell missed"></span> missed</span>
+</div>
+<div id="heatmaps"></div>
+
+<h2>Recent slashing events</h2>
+<table id="slashings">
+  <thead><tr><th>Offender</th><th>Kind</th><th>Level</th><th>Cycle</th><th>Amount (ꜩ)</th></tr></thead>
+  <tbody></tbody>
+</table>
+
+<script>
+function get(path) {
+  return fetch(path).then(function (r) {
+    if (!r.ok) { throw new Error(path + ": " + r.status); }
+    return r.json();
+  });
+}
+
+function cell(l) {
+  var c = document.createElement("div");
+  c.className = "cell";
+  if (l.baking === "missed" || (l.endorsement_slots > 0 && l.endorsements_missed === l.endorsement_slots)) {
+    c.className += " missed";
+  } else if (l.endorsements_missed > 0) {
+    c.className += " partial";
+  } else if (l.baking === "stolen") {
+    c.className += " stolen";
+  } else if (l.baking === "baked") {
+    c.className += " baked";
+  } else if (l.endorsement_slots > 0) {
+    c.className += " endorsed";
+  }
+  c.title = "Level " + l.level + (l.baking ? " " + l.baking : "") +
+    (l.endorsement_slots > 0 ? ", " + (l.endorsement_slots - l.endorsements_missed) + "/" + l.endorsement_slots + " slots" : "");
+  return c;
+}
+
+// el builds a node with text content, never parsing RPC or config strings as markup
+function el(tag, text, bad) {
+  var e = document.createElement(tag);
+  e.textContent = text;
+  if (bad) { e.className = "bad"; }
+  return e;
+}
+
+function row(cells) {
+  var tr = document.createElement("tr");
+  cells.forEach(function (c) { tr.appendChild(c); });
+  return tr;
+}
+
+function refresh() {
+  var node = document.getElementById("node");
+  get("/api/node").then(function (n) {
+    var lag = Math.round(n.lag_seconds);
+    node.textContent = "Node head ";
+    node.appendChild(el("code", n.block));
+    node.appendChild(document.createTextNode(", lag "));
+    node.appendChild(el("span", lag + "s", lag > 300));
+  }).catch(function (e) {
+    node.textContent = "";
+    node.appendChild(el("span", e.message, true));
+  });
+
+  var count = document.getElementById("count").value;
+  get("/api/delegates").then(function (delegates) {
+    var rows = document.querySelector("#bakers tbody");
+    var heatmaps = document.getElementById("heatmaps");
+    rows.textContent = "";
+    heatmaps.textContent = "";
+    delegates.forEach(function (d) {
+      var r = d.cycle_record;
+      rows.appendChild(row([
+        el("td", d.alias), el("td", d.baking_cycle), el("td", r.baking_rights), el("td", r.baked),
+        el("td", r.stolen), el("td", r.missed, r.missed), el("td", r.endorsement_slots),
+        el("td", r.endorsement_slots_missed, r.endorsement_slots_missed),
+        el("td", Math.max(d.last_baking_level, d.last_endorsement_level))
+      ]));
+
+      var heatmap = document.createElement("div");
+      heatmap.className = "heatmap";
+      heatmaps.appendChild(el("h3", d.alias));
+      heatmaps.appendChild(heatmap);
+      get("/api/delegates/" + encodeURIComponent(d.delegate) + "/levels?count=" + encodeURIComponent(count)).then(function (levels) {
+        levels.forEach(function (l) { heatmap.appendChild(cell(l)); });
+      });
+    });
+  });
+
+  get("/api/slashings").then(function (slashings) {
+    var rows = document.querySelector("#slashings tbody");
+    rows.textContent = "";
+    slashings.forEach(function (s) {
+      rows.appendChild(row([
+        el("td", s.alias, s.ours), el("td", s.kind), el("td", s.level), el("td", s.cycle), el("td", s.amount / 1e6)
+      ]));
+    });
+  });
+}
+
+document.getElementById("count").addEventListener("change", refresh);
+refresh();
+setInterval(refresh, 30000);
+</script>
+</body>
+</html>
+`
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"gitlab.com/polychainlabs/tezos-network-monitor/alert"
+	"gitlab.com/polychainlabs/tezos-network-monitor/monitor"
+	"gitlab.com/polychainlabs/tezos-network-monitor/storage"
+	"gitlab.com/polychainlabs/tezos-network-monitor/tzrpc"
+)
+
+// backfill scans a range of past levels with every analyzer, recording
+// results without sending alerts, and prints what would have been alerted
+func backfill(m *monitor.Monitor, c *config, args []string) {
+	flags := flag.NewFlagSet("backfill", flag.ExitOnError)
+	from := flags.Int64("from", -1, "First level to scan")
+	to := flags.Int64("to", -1, "Last level to scan")
+	cycle := flags.Int64("cycle", -1, "Scan this whole cycle instead of -from through -to")
+	flags.Parse(args)
+
+	if *cycle >= 0 {
+		*from, *to = tzrpc.CycleLevels(*cycle)
+	}
+	if *from < 1 || *to < *from {
+		logger.Fatalln("backfill needs -cycle, or -from and -to with from <= to")
+	}
+
+	alert.SetDryRun(true)
+	logger.Infof("Backfilling levels %v through %v", *from, *to)
+	m.Backfill(*from, *to, c.Workers)
+
+	printBackfillSummary(os.Stdout, c, *from, *to, alert.Captured())
+}
+
+// printBackfillSummary of each baker's record and every alert that would have
+// been sent
+func printBackfillSummary(w io.Writer, c *config, from int64, to int64, alerts []alert.Record) {
+	fmt.Fprintf(w, "Backfilled levels %v through %v\n\n", from, to)
+
+	for _, baker := range c.Bakers {
+		var rights, baked, stolen, missed, slots, slotsMissed int64
+		for cycle := tzrpc.Cycle(from); cycle <= tzrpc.Cycle(to); cycle++ {
+			for _, b := range storage.GetCycleBakings(baker, cycle) {
+				if b.Level < from || b.Level > to {
+					continue
+				}
+				if b.DelegateRights >= 0 {
+					rights++
+				}
+				if b.DelegateBaked {
+					baked++
+				}
+				if b.DelegateStole {
+					stolen++
+				}
+				if b.DelegateMissed {
+					missed++
+				}
+			}
+			for _, e := range storage.GetCycleEndorsements(baker, cycle) {
+				if e.Level < from || e.Level > to {
+					continue
+				}
+				slots += int64(len(e.Rights))
+				slotsMissed += e.Misses
+			}
+		}
+		fmt.Fprintf(w, "%v (%v)\n", alert.Alias(c.Aliases, baker), baker)
+		fmt.Fprintf(w, "  Baking:      %v rights, %v baked, %v stolen, %v missed\n", rights, baked, stolen, missed)
+		fmt.Fprintf(w, "  Endorsing:   %v slots, %v missed\n", slots, slotsMissed)
+	}
+
+	// Levels of protocols we can't parse weren't analyzed
+	for _, r := range storage.GetUnsupportedProtocols() {
+		if r.LastLevel < from || r.FirstLevel > to {
+			continue
+		}
+		fmt.Fprintf(w, "\nSkipped levels %v through %v of unsupported protocol %v\n", r.FirstLevel, r.LastLevel, r.Protocol)
+	}
+
+	pages := 0
+	for _, a := range alerts {
+		if a.Notifier == "pagerduty" {
+			pages++
+		}
+	}
+	fmt.Fprintf(w, "\nWould have sent %v alerts, including %v pages:\n", len(alerts), pages)
+	for _, a := range alerts {
+		fmt.Fprintf(w, "  [%v] %v\n", a.Notifier, a.Text)
+	}
+}
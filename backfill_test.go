@@ -0,0 +1,42 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"gitlab.com/polychainlabs/tezos-network-monitor/alert"
+	"gitlab.com/polychainlabs/tezos-network-monitor/storage"
+)
+
+func TestPrintBackfillSummary(t *testing.T) {
+	c := &config{Bakers: []string{"tz1backfill"}, Aliases: map[string]string{"tz1backfill": "Backfilled"}}
+	storage.RecordBaking("tz1backfill", 100, 0, 0, 0, "BLa", "tz1backfill", nil)
+	storage.RecordBaking("tz1backfill", 101, 0, 1, 1, "BLb", "tz1backfill", []string{"tz1slow"})
+	storage.RecordBaking("tz1backfill", 102, 0, 0, 2, "BLc", "tz1other", nil)
+	storage.RecordBaking("tz1backfill", 200, 0, 0, 1, "BLd", "tz1other", nil)
+	storage.RecordEndorsement("tz1backfill", 100, 0, []int64{1, 2}, []int64{1}, "BLa")
+	storage.RecordEndorsement("tz1backfill", 101, 0, []int64{3}, []int64{3}, "BLb")
+	storage.RecordBlockProtocol(102, "PtUnknown", false)
+	storage.RecordBlockProtocol(103, "PtUnknown", false)
+
+	var out bytes.Buffer
+	printBackfillSummary(&out, c, 100, 102, []alert.Record{
+		{Notifier: "slack", Text: "*Missed Block* at level `102`"},
+		{Notifier: "pagerduty", Text: "Missed many blocks"},
+	})
+
+	for _, expected := range []string{
+		"Backfilled levels 100 through 102",
+		"Backfilled (tz1backfill)",
+		"Baking:      3 rights, 2 baked, 1 stolen, 1 missed",
+		"Endorsing:   3 slots, 1 missed",
+		"Skipped levels 102 through 103 of unsupported protocol PtUnknown",
+		"Would have sent 2 alerts, including 1 pages",
+		"[pagerduty] Missed many blocks",
+	} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("Expected %q in summary:\n%v", expected, out.String())
+		}
+	}
+}
@@ -2,28 +2,40 @@ package main
 
 import (
 	"io/ioutil"
-	"log"
 
+	"gitlab.com/polychainlabs/tezos-network-monitor/monitor"
 	yaml "gopkg.in/yaml.v2"
 )
 
 type config struct {
-	Delegators []string            `yaml:"Delegators"`
-	Bakers     []string            `yaml:"Bakers"`
-	Whitelist  map[string][]string `yaml:"Whitelist"`
-	Aliases    map[string]string   `yaml:"Aliases"`
+	Delegators   []string                       `yaml:"Delegators"`
+	Bakers       []string                       `yaml:"Bakers"`
+	Whitelist    map[string][]string            `yaml:"Whitelist"`
+	Aliases      map[string]string              `yaml:"Aliases"`
+	LogLevel     string                         `yaml:"LogLevel"`
+	LogFormat    string                         `yaml:"LogFormat"`
+	Workers      int                            `yaml:"Workers"`
+	RateLimit    float64                        `yaml:"RateLimit"`
+	Checks       map[string]monitor.CheckConfig `yaml:"Checks"`
+	CycleReports bool                           `yaml:"CycleReports"`
+	Protocols    map[string]string              `yaml:"Protocols"`
 }
 
 func loadConfig(file string) *config {
-	c := config{}
+	c := config{
+		LogLevel:  "info",
+		LogFormat: "logfmt",
+		Workers:   4,
+		RateLimit: 20,
+	}
 
 	yamlFile, err := ioutil.ReadFile(file)
 	if err != nil {
-		log.Fatalln("Unable to read file: ", file, err)
+		logger.Fatalln("Unable to read file: ", file, err)
 	}
 	err = yaml.Unmarshal(yamlFile, &c)
 	if err != nil {
-		log.Fatalln("Unable to parse yaml file: ", file, err)
+		logger.Fatalln("Unable to parse yaml file: ", file, err)
 	}
 	return &c
 }
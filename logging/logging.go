@@ -0,0 +1,39 @@
+package logging
+
+import (
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Our own `level` field holds block levels, so logrus' level is renamed
+var fieldMap = logrus.FieldMap{
+	logrus.FieldKeyLevel: "severity",
+	logrus.FieldKeyMsg:   "msg",
+	logrus.FieldKeyTime:  "time",
+}
+
+// Configure the logger's minimum level (debug, info, warn, error) and format
+// (json or logfmt)
+func Configure(level string, format string) error {
+	parsed, err := logrus.ParseLevel(level)
+	if err != nil {
+		return err
+	}
+	logrus.SetLevel(parsed)
+
+	switch format {
+	case "json":
+		logrus.SetFormatter(&logrus.JSONFormatter{FieldMap: fieldMap})
+	case "logfmt", "":
+		logrus.SetFormatter(&logrus.TextFormatter{FieldMap: fieldMap, DisableColors: true, FullTimestamp: true})
+	default:
+		return fmt.Errorf("Unknown log format %v", format)
+	}
+	return nil
+}
+
+// Component logger, tagging every line with the component name
+func Component(name string) *logrus.Entry {
+	return logrus.WithField("component", name)
+}
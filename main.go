@@ -2,39 +2,96 @@ package main
 
 import (
 	"context"
-	"log"
+	"flag"
+	"net/http"
+	"os"
 	"time"
 
+	"gitlab.com/polychainlabs/tezos-network-monitor/api"
+	"gitlab.com/polychainlabs/tezos-network-monitor/logging"
 	"gitlab.com/polychainlabs/tezos-network-monitor/monitor"
+	"gitlab.com/polychainlabs/tezos-network-monitor/report"
+	"gitlab.com/polychainlabs/tezos-network-monitor/tzrpc"
 )
 
+var logger = logging.Component("main")
+
 func main() {
-	log.Println("Starting up...")
+	logLevel := flag.String("log-level", "", "Minimum log level: debug, info, warn or error. Overrides config.yaml")
+	logFormat := flag.String("log-format", "", "Log format: json or logfmt. Overrides config.yaml")
+	flag.Parse()
 
 	// Setup
 	ctx := context.Background()
 	c := loadConfig("./config.yaml")
+	if len(*logLevel) > 0 {
+		c.LogLevel = *logLevel
+	}
+	if len(*logFormat) > 0 {
+		c.LogFormat = *logFormat
+	}
+	if err := logging.Configure(c.LogLevel, c.LogFormat); err != nil {
+		logger.Fatalln("Unable to configure logging: ", err)
+	}
+	logger.Info("Starting up...")
+	tzrpc.SetRateLimit(c.RateLimit)
+	if err := tzrpc.LoadCycleEras(); err != nil {
+		logger.WithError(err).Warn("Unable to load cycle eras.  Using built in ones")
+	}
+	for hash, name := range c.Protocols {
+		tzrpc.SupportProtocol(hash, name)
+	}
 	addresses := append(c.Bakers, c.Delegators...)
 
 	// Monitor
 	monitor := monitor.New(ctx, addresses, c.Aliases, c.Whitelist)
+	monitor.Configure(c.Checks)
+	monitor.RegisterAnalyzers(monitor.DefaultAnalyzers(c.Bakers, c.Workers)...)
+	monitor.RegisterChecks(monitor.DefaultChecks(c.Bakers, c.Workers)...)
+	if c.CycleReports {
+		monitor.RegisterAnalyzers(report.NewAnalyzer(c.Bakers, c.Aliases))
+	}
 
-	for {
-		// Alert if network has stopped
-		monitor.CheckNode()
+	// Subcommands
+	switch flag.Arg(0) {
+	case "":
+	case "backfill":
+		backfill(monitor, c, flag.Args()[1:])
+		return
+	case "report":
+		printReport(monitor, c, flag.Args()[1:])
+		return
+	default:
+		logger.Fatalln("Unknown command: ", flag.Arg(0))
+	}
 
-		// Monitor Blocks
-		monitor.CheckBlocks()
+	// Serve metrics and status
+	go serve(listenAddr(), api.New(c.Bakers, c.Aliases).Handler())
+	go monitor.WatchMempool(c.Bakers)
 
-		// Monitor Endoring and Baking Trends
-		for _, d := range c.Bakers {
-			monitor.CheckBaking(d)
-			monitor.CheckEndorsing(d)
-		}
+	for {
+		// Alert if network has stopped, monitor blocks for transactions,
+		// delegations, slashing, and baking and endorsing trends, and export
+		// balances
+		monitor.RunChecks()
 
 		// Sleep
 		sleepSeconds := 5 * time.Second
-		log.Printf("Successfully caught up.  Sleeping for %v\n", sleepSeconds)
+		logger.Infof("Successfully caught up.  Sleeping for %v", sleepSeconds)
 		time.Sleep(sleepSeconds)
 	}
 }
+
+// serve the metrics and status endpoints
+func serve(addr string, handler http.Handler) {
+	logger.Infoln("Serving metrics and status on", addr)
+	logger.Fatal(http.ListenAndServe(addr, handler))
+}
+
+// listenAddr for the http server, defaulting to :8080
+func listenAddr() string {
+	if addr := os.Getenv("LISTEN_ADDR"); len(addr) > 0 {
+		return addr
+	}
+	return ":8080"
+}
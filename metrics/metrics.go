@@ -0,0 +1,224 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const namespace = "tezos_monitor"
+
+var (
+	// HeadLevel of the node we're monitoring
+	HeadLevel = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "head_level",
+		Help:      "Level of the current head block",
+	})
+
+	// NodeLag in seconds as reported by the bootstrapped RPC
+	NodeLag = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "node_lag_seconds",
+		Help:      "Seconds between now and the timestamp of the node's head block",
+	})
+
+	// LastProcessedLevel by each checker
+	LastProcessedLevel = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "last_processed_level",
+		Help:      "Last level analyzed by each checker",
+	}, []string{"checker", "delegate"})
+
+	// BlocksBaked by our delegates
+	BlocksBaked = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "blocks_baked_total",
+		Help:      "Blocks baked per delegate and cycle",
+	}, []string{"delegate", "cycle"})
+
+	// BlocksMissed by our delegates
+	BlocksMissed = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "blocks_missed_total",
+		Help:      "Blocks missed per delegate and cycle",
+	}, []string{"delegate", "cycle"})
+
+	// BlocksStolen by our delegates from a higher priority baker
+	BlocksStolen = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "blocks_stolen_total",
+		Help:      "Blocks baked at a non-zero priority per delegate and cycle",
+	}, []string{"delegate", "cycle"})
+
+	// BlocksTaken from our delegates by other bakers.  Who baked them is kept
+	// in storage, since any baker on the network could
+	BlocksTaken = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "blocks_taken_total",
+		Help:      "Blocks missed per delegate that another baker baked instead",
+	}, []string{"delegate"})
+
+	// EndorsementSlotsMade by our delegates
+	EndorsementSlotsMade = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "endorsement_slots_made_total",
+		Help:      "Endorsement slots included on chain per delegate and cycle",
+	}, []string{"delegate", "cycle"})
+
+	// EndorsementMisses by our delegates, by what the mempool saw of them
+	EndorsementMisses = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "endorsement_misses_total",
+		Help:      "Levels with missed endorsements per delegate and reason",
+	}, []string{"delegate", "reason"})
+
+	// EndorsementSlotsMissed by our delegates
+	EndorsementSlotsMissed = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "endorsement_slots_missed_total",
+		Help:      "Endorsement slots missed per delegate and cycle",
+	}, []string{"delegate", "cycle"})
+
+	// Rewards attributed to our bakers, in tez
+	Rewards = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rewards_tez_total",
+		Help:      "Rewards, fees, deposits and unfreezing per baker, cycle and category in tez",
+	}, []string{"delegate", "cycle", "category"})
+
+	// RewardsLost by our bakers over a completed cycle, in tez
+	RewardsLost = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "rewards_lost_tez",
+		Help:      "Expected minus earned baking and endorsing rewards per baker and cycle in tez",
+	}, []string{"delegate", "cycle"})
+
+	// SeedNoncesForfeited by our bakers, whose revelation window closed
+	// without one
+	SeedNoncesForfeited = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "seed_nonces_forfeited_total",
+		Help:      "Seed nonces committed per delegate and cycle that were never revealed",
+	}, []string{"delegate", "cycle"})
+
+	// CyclesUntilDeactivation of each baker, negative once deactivated
+	CyclesUntilDeactivation = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "cycles_until_deactivation",
+		Help:      "Cycles left in each baker's grace period before it's deactivated",
+	}, []string{"delegate"})
+
+	// StakingCapacity of each baker, in tez
+	StakingCapacity = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "staking_capacity_tez",
+		Help:      "Most staking balance each baker's own balance can cover, in tez",
+	}, []string{"delegate"})
+
+	// StakingHeadroom of each baker before it's over delegated, in tez
+	StakingHeadroom = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "staking_headroom_tez",
+		Help:      "Staking capacity minus staking balance per baker in tez, negative when over delegated",
+	}, []string{"delegate"})
+
+	// ForecastDeposits each baker needs for an upcoming cycle, in tez
+	ForecastDeposits = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "forecast_deposits_tez",
+		Help:      "Security deposits required per baker and current or upcoming cycle in tez",
+	}, []string{"delegate", "cycle"})
+
+	// ForecastAvailable balance of each baker after an upcoming cycle's
+	// deposits, in tez
+	ForecastAvailable = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "forecast_available_tez",
+		Help:      "Spendable balance left per baker after each upcoming cycle's deposits in tez, negative if short",
+	}, []string{"delegate", "cycle"})
+
+	// Delegators of each baker
+	Delegators = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "delegators",
+		Help:      "Contracts delegated to each baker",
+	}, []string{"delegate"})
+
+	// DelegationChange to each baker, in tez
+	DelegationChange = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "delegation_change_tez",
+		Help:      "Net balance of contracts that joined minus those that left per baker and cycle in tez",
+	}, []string{"delegate", "cycle"})
+
+	// DelegateDrains of our bakers by their consensus key
+	DelegateDrains = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "delegate_drains_total",
+		Help:      "Drain operations emptying a baker's balance, per baker",
+	}, []string{"delegate"})
+
+	// MempoolOperations pending in the node's mempool
+	MempoolOperations = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "mempool_operations",
+		Help:      "Operations pending in the mempool per classification",
+	}, []string{"status"})
+
+	// MempoolThreats flagged before inclusion
+	MempoolThreats = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "mempool_threats_total",
+		Help:      "Pending operations flagged in the mempool per kind",
+	}, []string{"kind"})
+
+	// UnsupportedBlocks skipped because of their protocol
+	UnsupportedBlocks = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "unsupported_blocks_total",
+		Help:      "Blocks whose protocol the monitor can't parse, per protocol",
+	}, []string{"protocol"})
+
+	// Balance of each configured address, in tez
+	Balance = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "balance_tez",
+		Help:      "Balance of each configured address in tez, including a baker's frozen balance",
+	}, []string{"address"})
+
+	// SpendableBalance of each configured address, in tez
+	SpendableBalance = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "spendable_balance_tez",
+		Help:      "Spendable balance of each configured address in tez",
+	}, []string{"address"})
+
+	// StakingBalance of each baker, in tez
+	StakingBalance = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "staking_balance_tez",
+		Help:      "Staking balance of each baker in tez",
+	}, []string{"address"})
+
+	// RPCDuration of each node endpoint we query
+	RPCDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "rpc_duration_seconds",
+		Help:      "Latency of node RPC calls per endpoint",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"endpoint"})
+
+	// CacheRequests to each node response cache
+	CacheRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cache_requests_total",
+		Help:      "Cache lookups per cache and result (hit or miss)",
+	}, []string{"cache", "result"})
+
+	// AlertsSent through each notifier
+	AlertsSent = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "alerts_sent_total",
+		Help:      "Alerts sent per notifier",
+	}, []string{"notifier"})
+)
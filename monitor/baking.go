@@ -2,64 +2,111 @@ package monitor
 
 import (
 	"fmt"
-	"log"
+	"strings"
 
 	"github.com/nlopes/slack"
+	"github.com/sirupsen/logrus"
 	"gitlab.com/polychainlabs/tezos-network-monitor/alert"
+	"gitlab.com/polychainlabs/tezos-network-monitor/metrics"
 	"gitlab.com/polychainlabs/tezos-network-monitor/storage"
 	"gitlab.com/polychainlabs/tezos-network-monitor/tzrpc"
 )
 
-// CheckBaking performance for this delegate
-func (m *Monitor) CheckBaking(delegate string) {
-	currentBlock := m.getCurrentBlock()
+// bakingAnalyzer of each baker's performance
+type bakingAnalyzer struct {
+	*Monitor
+	bakers  []string
+	workers int
+}
+
+func (a bakingAnalyzer) Name() string {
+	return "baking"
+}
+
+func (a bakingAnalyzer) Analyze(b *BlockContext) {
+	a.analyzeDelegates(b, a.bakers, a.workers, a.analyzeDelegate)
+}
 
-	// Get last checked level from firestore
-	lastRecordedLevel := storage.GetLastRecordedBakeLevel(delegate)
+// analyzeDelegate baking performance at this block
+func (a bakingAnalyzer) analyzeDelegate(b *BlockContext, delegate string) {
+	m := a.Monitor
+	level := b.Level
 
-	// Baking rights are only availabe so many levels behind, so start no earlier than 1 cycle ago
-	if lastRecordedLevel == -1 || lastRecordedLevel < currentBlock.Level()-4096 {
-		minLevel := currentBlock.Level() - 2
-		m.logError(fmt.Errorf("[Baking]\tLast recorded level of %v is too low.  Resetting to %v",
-			lastRecordedLevel, minLevel))
-		lastRecordedLevel = minLevel
+	// Get rights at level
+	delegateRights, err := tzrpc.BakingPriority(level, delegate)
+	m.check(err)
+
+	blockHash := b.Block.Hash()
+	bakerPriority := b.Block.BakerPriority()
+	baker := b.Block.Baker()
+	levelLogger := b.Logger.WithFields(logrus.Fields{
+		"delegate": delegate,
+		"rights":   delegateRights,
+		"priority": bakerPriority,
+		"baker":    baker,
+	})
+
+	// Who we stole from, when we baked at a lower priority
+	var stolenFrom []string
+	if bakerPriority == delegateRights && delegateRights > 0 {
+		stolenFrom = stolenFromBakers(level, delegateRights)
 	}
 
-	// Analyze baking rights
-	for level := lastRecordedLevel + 1; level < currentBlock.Level(); level++ {
-		debugf("[Baking]\tAnalyzing level %v for %v\n", level, delegate)
-		// Get rights at level
-		bakingRights, err := tzrpc.GetBakingRights(level)
-		m.check(err)
-		delegateRights := bakingRights.GetBakingPriority(delegate)
-
-		// Get remainder of metadata from blocks
-		block, err := tzrpc.GetBlock(currentBlock.Hash(), currentBlock.Level()-level)
-		m.check(err)
-
-		blockHash := block.Hash()
-		bakerPriority := block.BakerPriority()
-
-		// Alert on misses
-		if delegateRights >= 0 && bakerPriority > delegateRights {
-			// Slack when you miss a block
-			alert.PostSlack(&slack.WebhookMessage{
-				Text: fmt.Sprintf("*Missed Block* at level `%v` by `%v`", level, m.alias(delegate)),
-			})
-
-			// Machine parseable logline
-			log.Printf("baker=%v level=%v miss=1\n", delegate, level)
-
-			// Page if we've missed a lot this cycle
-			m.checkBakingTrends(delegate)
-		} else if bakerPriority == delegateRights {
-			// Machine parseable logline when we've baked
-			log.Printf("baker=%v level=%v miss=0\n", delegate, level)
+	// Save to Datastore
+	storage.RecordBaking(delegate, level, b.Block.Cycle(), delegateRights, bakerPriority, blockHash, baker, stolenFrom)
+
+	// Alert on misses
+	if delegateRights >= 0 && bakerPriority > delegateRights {
+		// Slack when you miss a block
+		alert.PostSlack(&slack.WebhookMessage{
+			Text: fmt.Sprintf("*Missed Block* at level `%v` by `%v`, baked by `%v` at priority `%v`",
+				level, m.alias(delegate), m.alias(baker), bakerPriority),
+		})
+
+		levelLogger.WithField("miss", 1).Info("Missed block")
+		metrics.BlocksMissed.WithLabelValues(delegate, cycle(level)).Inc()
+		metrics.BlocksTaken.WithLabelValues(delegate).Inc()
+
+		// Page if we've missed a lot this cycle
+		m.checkBakingTrends(delegate)
+		m.checkBlockTakers(delegate, baker, b.Block.Cycle())
+	} else if bakerPriority == delegateRights {
+		levelLogger.WithField("miss", 0).Info("Baked block")
+		metrics.BlocksBaked.WithLabelValues(delegate, cycle(level)).Inc()
+		if delegateRights > 0 {
+			levelLogger.WithField("stolen_from", strings.Join(stolenFrom, ",")).Info("Stole block")
+			metrics.BlocksStolen.WithLabelValues(delegate, cycle(level)).Inc()
+		}
+	}
+	metrics.LastProcessedLevel.WithLabelValues("baking", delegate).Set(float64(level))
+}
+
+// stolenFromBakers with rights to bake at `level` ahead of `priority`
+func stolenFromBakers(level int64, priority int64) []string {
+	rights, err := tzrpc.GetBakingRights(level)
+	if err != nil {
+		return nil
+	}
+	bakers := []string{}
+	for p := int64(0); p < priority; p++ {
+		if baker := rights.Delegate(p); len(baker) > 0 {
+			bakers = append(bakers, baker)
 		}
+	}
+	return bakers
+}
 
-		// Save to Datastore
-		storage.RecordBaking(delegate, level, delegateRights, bakerPriority, blockHash)
+// checkBlockTakers and alert once a cycle when a single baker keeps baking
+// the blocks we miss
+func (m *Monitor) checkBlockTakers(delegate string, taker string, c int64) {
+	taken := storage.GetCycleBlockTakers(delegate, c)[taker]
+	if float64(taken) < m.threshold("baking", delegate, "SlackRepeatTakes", 2) || !storage.MarkTakerWarned(delegate, c, taker) {
+		return
 	}
+	alert.PostSlack(&slack.WebhookMessage{
+		Text: fmt.Sprintf("*Repeat Block Taker* `%v` has baked `%v` of the blocks `%v` missed in cycle `%v`",
+			m.alias(taker), taken, m.alias(delegate), c),
+	})
 }
 
 // checkBakingTrends and page if you've missed a lot this cycle
@@ -68,7 +115,7 @@ func (m *Monitor) checkBakingTrends(delegate string) {
 	misses, _ := storage.GetCycleBakeMissCount(delegate)
 
 	// Page if miss > 2 bakings per cycle
-	if misses > 2 {
+	if float64(misses) > m.threshold("baking", delegate, "PageCycleMisses", 2) {
 		title := fmt.Sprintf("Missed many blocks by %v", delegate)
 		body := fmt.Sprintf("Missed %v blocks.  Is this baker online?", misses)
 		alert.Page(title, body)
@@ -0,0 +1,108 @@
+package monitor
+
+import (
+	"fmt"
+	"testing"
+
+	"gitlab.com/polychainlabs/tezos-network-monitor/logging"
+	"gitlab.com/polychainlabs/tezos-network-monitor/storage"
+	"gitlab.com/polychainlabs/tezos-network-monitor/tzrpc"
+)
+
+func TestBlockTakers(t *testing.T) {
+	alerts := captureAlerts()
+	m := Monitor{}
+	delegate := "tz1takenfrom"
+	level := int64(4096*300 + 10)
+	c := tzrpc.Cycle(level)
+
+	// Missed at priority 0, baked by others at priority 1
+	storage.RecordBaking(delegate, level, c, 0, 1, "BLa", "tz1taker", nil)
+	m.checkBlockTakers(delegate, "tz1taker", c)
+	storage.RecordBaking(delegate, level+1, c, 0, 1, "BLb", "tz1other", nil)
+	m.checkBlockTakers(delegate, "tz1other", c)
+	if len(alerts()) != 0 {
+		t.Error("Expected no warning after a single take", alerts())
+	}
+
+	// Warned once a cycle
+	storage.RecordBaking(delegate, level+2, c, 0, 1, "BLc", "tz1taker", nil)
+	m.checkBlockTakers(delegate, "tz1taker", c)
+	m.checkBlockTakers(delegate, "tz1taker", c)
+	if countAlerts(alerts(), "slack", "*Repeat Block Taker* `tz1tak...` has baked `2`") != 1 {
+		t.Error("Expected a warning after two takes by the same baker", alerts())
+	}
+	if takers := storage.GetCycleBlockTakers(delegate, c); takers["tz1taker"] != 2 || takers["tz1other"] != 1 {
+		t.Error("Incorrect block takers", takers)
+	}
+
+	// Takes are counted per cycle
+	_, last := tzrpc.CycleLevels(c)
+	storage.RecordBaking(delegate, last+1, c+1, 0, 1, "BLnext", "tz1taker", nil)
+	m.checkBlockTakers(delegate, "tz1taker", c+1)
+	if len(alerts()) != 1 || storage.GetCycleBlockTakers(delegate, c+1)["tz1taker"] != 1 {
+		t.Error("Expected takes to start over in the next cycle", alerts())
+	}
+
+	// Stole at priority 2
+	storage.RecordBaking(delegate, level+3, c, 2, 2, "BLd", delegate, []string{"tz1slow", "tz1slower"})
+	if victims := storage.GetCycleStolenFrom(delegate, c); victims["tz1slow"] != 1 || victims["tz1slower"] != 1 {
+		t.Error("Incorrect stolen from", victims)
+	}
+	bakings := storage.GetBakings(delegate, 1)
+	if !bakings[0].DelegateStole || bakings[0].Baker != delegate || len(bakings[0].StolenFrom) != 2 {
+		t.Errorf("Incorrect stolen block %+v", bakings[0])
+	}
+}
+
+func TestAnalyzeBaking(t *testing.T) {
+	alerts := captureAlerts()
+	delegate := "tz1bakinganalyzed"
+	routes := map[string]string{}
+	block := func(level int64, baker string, priority int64) {
+		routes[fmt.Sprintf("chains/main/blocks/BL%v~0", level)] = fmt.Sprintf(`{
+			"hash": "BL%v",
+			"header": {"level": %v, "predecessor": "BL%v", "priority": %v},
+			"metadata": {"protocol": "PsQuebecnLByd3JwTiGadoG4nGWi3HYiLXUjkibeFV8dCFeVMUg", "baker": "%v"},
+			"operations": [[], [], [], []]
+		}`, level, level, level-1, priority, baker)
+	}
+	rights := func(level int64, r string) {
+		routes[fmt.Sprintf("chains/main/blocks/%v/helpers/baking_rights?level=%v", level, level)] = r
+		routes[fmt.Sprintf("chains/main/blocks/head/helpers/baking_rights?level=%v", level)] = r
+	}
+	block(9001, "tz1bakingthief", 1)
+	rights(9001, `[{"level": 9001, "delegate": "tz1bakinganalyzed", "priority": 0}, {"level": 9001, "delegate": "tz1bakingthief", "priority": 1}]`)
+	block(9002, delegate, 2)
+	rights(9002, `[{"level": 9002, "delegate": "tz1slow", "priority": 0}, {"level": 9002, "delegate": "tz1slower", "priority": 1},
+		{"level": 9002, "delegate": "tz1bakinganalyzed", "priority": 2}]`)
+	block(9003, "tz1bakingthief", 1)
+	rights(9003, `[{"level": 9003, "delegate": "tz1bakinganalyzed", "priority": 0}, {"level": 9003, "delegate": "tz1bakingthief", "priority": 1}]`)
+	defer serveNode(routes)()
+
+	a := bakingAnalyzer{&Monitor{}, []string{delegate}, 1}
+	for level := int64(9001); level <= 9003; level++ {
+		block, err := tzrpc.GetBlock(fmt.Sprintf("BL%v", level), 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		a.analyzeDelegate(&BlockContext{Level: level, Block: block, Logger: logging.Component("test")}, delegate)
+	}
+
+	// Misses alert, and the baker that keeps taking them is called out
+	if countAlerts(alerts(), "slack", "*Missed Block* at level `9001`") != 1 || countAlerts(alerts(), "slack", "*Missed Block* at level `9003`") != 1 {
+		t.Error("Expected an alert for each missed block", alerts())
+	}
+	if countAlerts(alerts(), "slack", "*Repeat Block Taker*") != 1 {
+		t.Error("Expected a warning for the repeat taker", alerts())
+	}
+
+	// Blocks baked at a lower priority record who had it first
+	bakings := storage.GetBakings(delegate, 3)
+	if len(bakings) != 3 || !bakings[1].DelegateStole || fmt.Sprint(bakings[1].StolenFrom) != "[tz1slow tz1slower]" {
+		t.Errorf("Incorrect bakings %+v", bakings)
+	}
+	if !bakings[0].DelegateMissed || bakings[0].Baker != "tz1bakingthief" {
+		t.Errorf("Incorrect missed block %+v", bakings[0])
+	}
+}
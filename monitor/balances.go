@@ -0,0 +1,92 @@
+package monitor
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+
+	"github.com/nlopes/slack"
+	"gitlab.com/polychainlabs/tezos-network-monitor/alert"
+	"gitlab.com/polychainlabs/tezos-network-monitor/logging"
+	"gitlab.com/polychainlabs/tezos-network-monitor/metrics"
+	"gitlab.com/polychainlabs/tezos-network-monitor/storage"
+	"gitlab.com/polychainlabs/tezos-network-monitor/tzrpc"
+)
+
+// CheckBalances of every address and staking balances of these bakers, at
+// most `workers` at a time
+func (m *Monitor) CheckBalances(bakers []string, workers int) {
+	head := m.getCurrentBlock()
+	checks := []func(){}
+	for _, b := range bakers {
+		baker := b
+		checks = append(checks, func() { m.CheckStakingBalance(baker) })
+	}
+	for _, a := range m.addresses {
+		address := a
+		checks = append(checks, func() { m.CheckBalance(address, head) })
+	}
+	m.RunConcurrently(workers, checks)
+}
+
+// CheckBalance of this address at `head`, export and record it, and alert if
+// it's too low or changed a lot since the last cycle
+func (m *Monitor) CheckBalance(address string, head *tzrpc.Block) {
+	balance, err := tzrpc.GetBalanceAt(address, head.Hash())
+	if err != nil {
+		logging.Component("balance").WithError(err).WithField("address", address).Error("Unable to get balance")
+		return
+	}
+	spendable, err := tzrpc.GetSpendableBalanceAt(address, head.Hash())
+	if err != nil {
+		logging.Component("balance").WithError(err).WithField("address", address).Error("Unable to get spendable balance")
+		return
+	}
+	metrics.Balance.WithLabelValues(address).Set(toFloat(balance))
+	metrics.SpendableBalance.WithLabelValues(address).Set(toFloat(spendable))
+	m.updateBalance(address, head.Level(), balance.Int64(), spendable.Int64())
+}
+
+// updateBalance of `address` in tez at `level`.  The minimum applies to its
+// `spendable` balance, large changes to its full `balance`
+func (m *Monitor) updateBalance(address string, level int64, balance int64, spendable int64) {
+	previous := storage.GetBalances(address, 1)
+	storage.RecordBalance(address, level, balance, spendable)
+
+	// Slack when falling below the minimum
+	minBalance := m.threshold("balances", address, "SlackMinBalance", 0)
+	wasAbove := len(previous) == 0 || float64(previous[0].Spendable) >= minBalance
+	if float64(spendable) < minBalance && wasAbove {
+		alert.PostSlack(&slack.WebhookMessage{
+			Text: fmt.Sprintf("*Low Balance* `%v` has `%vꜩ` spendable, below the minimum of `%vꜩ`", m.alias(address), spendable, minBalance),
+		})
+	}
+
+	// Slack once a cycle on large changes since the end of the last one
+	currentCycle := tzrpc.Cycle(level)
+	closing, ok := storage.GetClosingBalance(address, currentCycle-1)
+	if !ok || closing.Balance == 0 {
+		return
+	}
+	change := 100 * float64(balance-closing.Balance) / float64(closing.Balance)
+	if math.Abs(change) >= m.threshold("balances", address, "SlackChangePercent", 10) && storage.MarkBalanceChangeAlerted(address, currentCycle) {
+		alert.PostSlack(&slack.WebhookMessage{
+			Text: fmt.Sprintf("*Balance Change* `%v` has `%vꜩ`, `%+.1f%%` since the end of cycle `%v`", m.alias(address), balance, change, currentCycle-1),
+		})
+	}
+}
+
+// CheckStakingBalance of this baker and export it
+func (m *Monitor) CheckStakingBalance(baker string) {
+	balance, err := tzrpc.GetStakingBalance(baker)
+	if err != nil {
+		logging.Component("balance").WithError(err).WithField("address", baker).Error("Unable to get staking balance")
+		return
+	}
+	metrics.StakingBalance.WithLabelValues(baker).Set(toFloat(balance))
+}
+
+func toFloat(i *big.Int) float64 {
+	f, _ := new(big.Float).SetInt(i).Float64()
+	return f
+}
@@ -0,0 +1,74 @@
+package monitor
+
+import (
+	"testing"
+
+	"gitlab.com/polychainlabs/tezos-network-monitor/storage"
+	"gitlab.com/polychainlabs/tezos-network-monitor/tzrpc"
+)
+
+func TestUpdateBalance(t *testing.T) {
+	alerts := captureAlerts()
+	m := Monitor{}
+	first, last := tzrpc.CycleLevels(200)
+
+	// A snapshot per level, and nothing to compare with in the first cycle
+	m.updateBalance("tz1balance", first, 5000, 100)
+	m.updateBalance("tz1balance", last, 1000, 100)
+	m.updateBalance("tz1balance", last, 5, 5)
+	if snapshots := storage.GetBalances("tz1balance", 10); len(snapshots) != 2 || snapshots[1].Spendable != 100 {
+		t.Error("Expected a snapshot per level but found", snapshots)
+	}
+	if len(alerts()) != 0 {
+		t.Error("Expected no alert within the first cycle", alerts())
+	}
+
+	// Changes are from the last balance of the previous cycle, alerting from
+	// exactly the threshold
+	m.updateBalance("tz1balance", last+1, 901, 50)
+	if len(alerts()) != 0 {
+		t.Error("Expected no alert for a 9.9% change", alerts())
+	}
+	m.updateBalance("tz1balance", last+2, 900, 50)
+	if countAlerts(alerts(), "slack", "has `900ꜩ`, `-10.0%` since the end of cycle `200`") != 1 {
+		t.Error("Expected an alert for a 10% change", alerts())
+	}
+
+	// Once a cycle, then from this cycle's close in the next
+	m.updateBalance("tz1balance", last+3, 100, 50)
+	if len(alerts()) != 1 {
+		t.Error("Expected a single alert in cycle 201", alerts())
+	}
+	_, next := tzrpc.CycleLevels(201)
+	m.updateBalance("tz1balance", next+1, 110, 50)
+	if countAlerts(alerts(), "slack", "has `110ꜩ`, `+10.0%` since the end of cycle `201`") != 1 {
+		t.Error("Expected an alert from the close of cycle 201", alerts())
+	}
+}
+
+func TestUpdateBalanceMinimum(t *testing.T) {
+	alerts := captureAlerts()
+	m := Monitor{config: map[string]CheckConfig{"balances": {Thresholds: map[string]float64{"SlackMinBalance": 100}}}}
+
+	// A baker's frozen balance doesn't count towards its minimum, and exactly
+	// the minimum is enough
+	m.updateBalance("tz1minimum", 1, 5000, 200)
+	m.updateBalance("tz1minimum", 2, 5000, 100)
+	if len(alerts()) != 0 {
+		t.Error("Expected no alert at the minimum", alerts())
+	}
+
+	// Alerts on falling below it, not while staying below
+	m.updateBalance("tz1minimum", 3, 5000, 99)
+	m.updateBalance("tz1minimum", 4, 5000, 40)
+	if countAlerts(alerts(), "slack", "has `99ꜩ` spendable") != 1 || len(alerts()) != 1 {
+		t.Error("Expected a single alert on falling below the minimum", alerts())
+	}
+
+	// Again after recovering
+	m.updateBalance("tz1minimum", 5, 5000, 100)
+	m.updateBalance("tz1minimum", 6, 5000, 50)
+	if countAlerts(alerts(), "slack", "has `50ꜩ` spendable") != 1 || len(alerts()) != 2 {
+		t.Error("Expected another alert after recovering", alerts())
+	}
+}
@@ -2,162 +2,240 @@ package monitor
 
 import (
 	"fmt"
-	"log"
+	"sync"
 
-	"github.com/nlopes/slack"
+	"github.com/sirupsen/logrus"
 	"gitlab.com/polychainlabs/tezos-network-monitor/alert"
+	"gitlab.com/polychainlabs/tezos-network-monitor/logging"
+	"gitlab.com/polychainlabs/tezos-network-monitor/metrics"
 	"gitlab.com/polychainlabs/tezos-network-monitor/storage"
 	"gitlab.com/polychainlabs/tezos-network-monitor/tzrpc"
 )
 
-// CheckBlocks and alerts if any error conditions are met
-func (m *Monitor) CheckBlocks() {
+// Analyzer of every new block.  Each analyzer alerts on and records its own
+// results
+type Analyzer interface {
+	// Name for logs and metrics
+	Name() string
+	// Analyze one block
+	Analyze(b *BlockContext)
+}
+
+// BlockContext handed to every analyzer
+type BlockContext struct {
+	Level  int64
+	Block  *tzrpc.Block
+	Logger *logrus.Entry
+}
+
+// predecessorAnalyzer analyzes the level before its block, like endorsements,
+// which are only included in the next block
+type predecessorAnalyzer interface {
+	AnalyzesPredecessor() bool
+}
+
+// analyzedLevel by `analyzer` when handed the block at `level`
+func analyzedLevel(analyzer Analyzer, level int64) int64 {
+	if p, ok := analyzer.(predecessorAnalyzer); ok && p.AnalyzesPredecessor() {
+		return level - 1
+	}
+	return level
+}
+
+// levelWindow of analyzed levels, from and to inclusive.  The zero window
+// allows every level
+type levelWindow struct {
+	from int64
+	to   int64
+}
+
+func (w levelWindow) contains(level int64) bool {
+	return w == levelWindow{} || (level >= w.from && level <= w.to)
+}
+
+// RegisterAnalyzers to run on every new block, in order
+func (m *Monitor) RegisterAnalyzers(analyzers ...Analyzer) {
+	m.analyzers = append(m.analyzers, analyzers...)
+}
+
+// DefaultAnalyzers for our addresses and these bakers, analyzing up to
+// `workers` bakers' baking and endorsing at a time
+func (m *Monitor) DefaultAnalyzers(bakers []string, workers int) []Analyzer {
+	return []Analyzer{
+		protocolAnalyzer{m},
+		transactionAnalyzer{m},
+		delegationAnalyzer{m},
+		originationAnalyzer{m},
+		slashingAnalyzer{m},
+		bakingAnalyzer{m, bakers, workers},
+		endorsingAnalyzer{m, bakers, workers},
+		rewardsAnalyzer{m, bakers},
+		nonceAnalyzer{m, bakers},
+		governanceAnalyzer{m, bakers},
+		consensusKeyAnalyzer{m, bakers},
+	}
+}
+
+// CheckBlocks fetches each new block once, up to `workers` at a time, and
+// hands them to every registered analyzer in level order
+func (m *Monitor) CheckBlocks(workers int) {
 	currentBlock := m.getCurrentBlock()
+	logger := logging.Component("blocks")
 
 	// Get last checked level from firestore
 	lastRecordedLevel := storage.GetLastRecordedBlockLevel()
 
 	// Ignore blocks more than 1 cycle ago
-	if lastRecordedLevel == -1 || lastRecordedLevel < currentBlock.Level()-4096 {
+	previousCycle, _ := tzrpc.CycleLevels(tzrpc.Cycle(currentBlock.Level()) - 1)
+	if lastRecordedLevel == -1 || lastRecordedLevel < previousCycle {
 		minLevel := currentBlock.Level() - 2
-		m.logError(fmt.Errorf("[Block]\tLast recorded level of %v is too low.  Resetting to %v", lastRecordedLevel, minLevel))
+		logger.Warnf("Last recorded level of %v is too low.  Resetting to %v", lastRecordedLevel, minLevel)
 		lastRecordedLevel = minLevel
 	}
 
 	// Analyze all new blocks
-	for level := lastRecordedLevel + 1; level < currentBlock.Level(); level++ {
-		debugln("[Block]\tAnalyzing level ", level)
-
-		// Get block at level
-		block, err := tzrpc.GetBlock(currentBlock.Hash(), currentBlock.Level()-level)
-		m.check(err)
-
-		// Alert when transactions are sent or received
-		for _, tx := range block.Transactions() {
-			for _, address := range m.addresses {
-				if address == tx.Source {
-					// Slack when transactions sent _from_ your address
-					alert.PostSlack(&slack.WebhookMessage{
-						Text: fmt.Sprintf("*Sent* `%v`ꜩ from `%v` to `%v` with `%v`ꜩ fee",
-							tx.Amount/1e6, m.alias(tx.Source), m.alias(tx.Destination), tx.Fee/1e6),
-					})
-					// Page if destination address is not whitelisted
-					if !m.isDestinationWhitelisted(tx.Source, tx.Destination) {
-						title := fmt.Sprintf("Sent %vꜩ from %v", tx.Amount/1e6, m.alias(tx.Source))
-						body := fmt.Sprintf("To %v with fee %vꜩ at level %v",
-							m.alias(tx.Destination), tx.Fee/1e6, level)
-						alert.Page(title, body)
-					}
-				}
-				if address == tx.Destination {
-					// Slack when transactions sent _to_ your address
-					alert.PostSlack(&slack.WebhookMessage{
-						Text: fmt.Sprintf("*Received* `%v`ꜩ at `%v`", tx.Amount/1e6, m.alias(tx.Destination)),
-					})
-				}
-			}
-		}
+	m.analyzeRange(currentBlock, lastRecordedLevel+1, currentBlock.Level(), workers, logger, true, levelWindow{})
+}
 
-		// Slack when delegations received
-		for _, delegation := range block.Delegations() {
-			for _, address := range m.addresses {
-				if address == delegation.Delegate {
-					amount := getBalanceString(delegation.Source)
-					alert.PostSlack(&slack.WebhookMessage{
-						Text: fmt.Sprintf("*Delegation* `%v` delegated `%vꜩ` to `%v`",
-							m.alias(delegation.Source), amount, m.alias(delegation.Delegate)),
-					})
-				}
-				if address == delegation.Source {
-					amount := getBalanceString(delegation.Source)
-					alert.PostSlack(&slack.WebhookMessage{
-						Text: fmt.Sprintf("*Delegation* We delegated `%vꜩ`from `%v` to `%v`",
-							amount, m.alias(delegation.Source), m.alias(delegation.Delegate)),
-					})
-				}
-			}
+// Backfill runs every registered analyzer over levels `from` through `to`,
+// recording their results without moving the live cursor.  Endorsements of
+// `to` are in the following block, so it's fetched too
+func (m *Monitor) Backfill(from int64, to int64, workers int) {
+	currentBlock := m.getCurrentBlock()
+	logger := logging.Component("backfill")
+
+	if to >= currentBlock.Level() {
+		to = currentBlock.Level() - 1
+		logger.Warnf("Endorsements of the head aren't included yet.  Stopping at %v", to)
+	}
+	m.analyzeRange(currentBlock, from, to+2, workers, logger, false, levelWindow{from, to})
+}
+
+// analyzeRange of blocks [start, end), fetching a batch of `workers` blocks
+// at a time.  Analyzers only run for levels in `window`.  Moves the live
+// cursor if `advance`
+func (m *Monitor) analyzeRange(currentBlock *tzrpc.Block, start int64, end int64, workers int, logger *logrus.Entry, advance bool, window levelWindow) {
+	if workers < 1 {
+		workers = 1
+	}
+	for batchStart := start; batchStart < end; batchStart += int64(workers) {
+		batchEnd := batchStart + int64(workers)
+		if batchEnd > end {
+			batchEnd = end
 		}
+		blocks := m.fetchBlocks(currentBlock, batchStart, batchEnd, workers)
 
-		// Slack when delegations received through originations
-		for _, origination := range block.Originations() {
-			for _, address := range m.addresses {
-				if address == origination.Delegate {
-					alert.PostSlack(&slack.WebhookMessage{
-						Text: fmt.Sprintf("*Delegation* `%v` delegated `%vꜩ` to `%v` through an origination",
-							origination.Source, origination.Balance.String(), m.alias(origination.Delegate)),
-					})
-				}
-				if address == origination.Source {
-					alert.PostSlack(&slack.WebhookMessage{
-						Text: fmt.Sprintf("*Origination* We originated a contract from `%v`",
-							m.alias(origination.Source)),
-					})
-				}
-			}
+		for level := batchStart; level < batchEnd; level++ {
+			m.analyze(level, blocks[level], logger, advance, window)
 		}
+	}
+}
 
-		// Alert on double baking
-		for _, double := range block.DoubleBakings() {
-			// Slack if anyone has double baked
-			alert.PostSlack(&slack.WebhookMessage{
-				Text: fmt.Sprintf("*Double Baking* found at level `%v`. `%vꜩ` slashed", double.Level, double.SlashedAmount),
-			})
-			for _, address := range m.addresses {
-				if address == double.SlashedBaker {
-					// Page if you've double baked :(
-					alert.PostSlack(&slack.WebhookMessage{
-						Text: fmt.Sprintf("*WE DOUBLE BAKED* At level `%v` by baker `%v`. `%vꜩ` slashed. SHUT THIS BAKER DOWN NOW.", double.Level, double.SlashedBaker, double.SlashedAmount),
-					})
-
-					title := fmt.Sprintf("WE DOUBLE BAKED with %v", address)
-					body := fmt.Sprintf("%v was just slashed at level %v.  SHUT THIS BAKER DOWN NOW, AND STAY OFFLINE FOR THE REMAINED OF THE CYCLE.", double.SlashedAmount, level)
-					alert.Page(title, body)
-				}
-			}
+// fetchBlocks in [start, end) concurrently
+func (m *Monitor) fetchBlocks(currentBlock *tzrpc.Block, start int64, end int64, workers int) map[int64]*tzrpc.Block {
+	var lock sync.Mutex
+	blocks := map[int64]*tzrpc.Block{}
+
+	fetches := []func(){}
+	for l := start; l < end; l++ {
+		level := l
+		fetches = append(fetches, func() {
+			block, err := tzrpc.GetBlock(currentBlock.Hash(), currentBlock.Level()-level)
+			m.check(err)
+			lock.Lock()
+			defer lock.Unlock()
+			blocks[level] = block
+		})
+	}
+	m.RunConcurrently(workers, fetches)
+	return blocks
+}
+
+// analyze one block with every analyzer whose level is in `window`, and move
+// the cursor past it if `advance`
+func (m *Monitor) analyze(level int64, block *tzrpc.Block, logger *logrus.Entry, advance bool, window levelWindow) {
+	levelLogger := withLevel(logger, level).WithField("block_hash", block.Hash())
+	levelLogger.Debug("Analyzing level")
+
+	// Cycle lengths change with the protocol, so reload them when a block
+	// disagrees with ours
+	if block.Cycle() != tzrpc.Cycle(level) {
+		levelLogger.WithField("block_cycle", block.Cycle()).Warn("Cycle eras are out of date.  Reloading")
+		if err := tzrpc.LoadCycleEras(); err != nil {
+			levelLogger.WithError(err).Warn("Unable to reload cycle eras")
 		}
+	}
 
-		// Alert on double endorsements
-		for _, double := range block.DoubleEndorsements() {
-			// Slack if anyone has double endorsed
-			alert.PostSlack(&slack.WebhookMessage{
-				Text: fmt.Sprintf("*Double Endorsement* found at cycle `%v`. `%vꜩ` slashed", double.Cycle, double.SlashedAmount/1e6),
-			})
-			for _, address := range m.addresses {
-				if address == double.SlashedEndorser {
-					// Page if you've double endorsed :(
-					alert.PostSlack(&slack.WebhookMessage{
-						Text: fmt.Sprintf("*WE DOUBLE ENDORSED* At cycle `%v` by endorser `%v`. `%vꜩ` slashed. SHUT THIS ENDORSER DOWN NOW", double.Cycle, double.SlashedEndorser, double.SlashedAmount/1e6),
-					})
-
-					title := fmt.Sprintf("WE DOUBLE ENDORSED with %v at level %v", address, level)
-					body := fmt.Sprintf("%v was just slashed.  SHUT THIS ENDORSER DOWN NOW, AND STAY OFFLINE FOR THE REMAINED OF THE CYCLE", double.SlashedAmount)
-					alert.Page(title, body)
-				}
+	if m.supportedBlock(level, block, levelLogger) {
+		for _, analyzer := range m.analyzers {
+			if !m.enabled(analyzer.Name()) || !window.contains(analyzedLevel(analyzer, level)) {
+				continue
 			}
+			m.runAnalyzer(analyzer, &BlockContext{
+				Level:  level,
+				Block:  block,
+				Logger: levelLogger.WithField("component", analyzer.Name()),
+			})
 		}
+	}
 
-		// Save
-		storage.RecordBlock(level, block.Hash())
+	// Save
+	if !advance {
+		return
 	}
+	storage.RecordBlock(level, block.Hash())
+	metrics.LastProcessedLevel.WithLabelValues("blocks", "").Set(float64(level))
 }
 
-func (m *Monitor) isDestinationWhitelisted(source string, destination string) bool {
-	if _, ok := m.whitelist[source]; !ok {
-		return false
+// analyzeDelegates at this block, with at most `workers` at once.  A panic in
+// one is raised again once they've all finished, so runAnalyzer still pages
+func (m *Monitor) analyzeDelegates(b *BlockContext, delegates []string, workers int, analyze func(*BlockContext, string)) {
+	var lock sync.Mutex
+	var failure interface{}
+
+	checks := []func(){}
+	for _, d := range delegates {
+		delegate := d
+		checks = append(checks, func() {
+			defer func() {
+				if r := recover(); r != nil {
+					lock.Lock()
+					defer lock.Unlock()
+					failure = r
+				}
+			}()
+			analyze(b, delegate)
+		})
 	}
-	for _, address := range m.whitelist[source] {
-		if address == destination {
-			return true
-		}
+	m.RunConcurrently(workers, checks)
+	if failure != nil {
+		panic(failure)
 	}
-	return false
 }
 
-func getBalanceString(pkh string) string {
-	balance, err := tzrpc.GetBalance(pkh)
-	if err != nil {
-		log.Println(err)
-		return ""
+// runAnalyzer on one block, paging instead of crashing if the block's layout
+// breaks its parsing
+func (m *Monitor) runAnalyzer(analyzer Analyzer, b *BlockContext) {
+	defer func() {
+		if r := recover(); r != nil {
+			b.Logger.WithField("panic", r).Error("Analyzer failed")
+			title := fmt.Sprintf("The %v analyzer failed to parse a block", analyzer.Name())
+			body := fmt.Sprintf("Level %v (%v) couldn't be analyzed: %v.  Has the protocol changed?", b.Level, b.Block.Protocol(), r)
+			alert.Page(title, body)
+		}
+	}()
+	analyzer.Analyze(b)
+}
+
+func (m *Monitor) isOurs(address string) bool {
+	return contains(m.addresses, address)
+}
+
+func contains(addresses []string, address string) bool {
+	for _, a := range addresses {
+		if a == address {
+			return true
+		}
 	}
-	return balance.String()
+	return false
 }
@@ -1,7 +1,16 @@
 package monitor
 
 import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
 	"testing"
+	"time"
+
+	"gitlab.com/polychainlabs/tezos-network-monitor/alert"
+	"gitlab.com/polychainlabs/tezos-network-monitor/storage"
 )
 
 func TestWhitelist(t *testing.T) {
@@ -29,3 +38,83 @@ func TestWhitelist(t *testing.T) {
 		t.Fail()
 	}
 }
+
+// serveNode answers requests for the paths in `routes`, with their query, and
+// 404s the rest.  Returns a function restoring the previous node
+func serveNode(routes map[string]string) func() {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := routes[strings.TrimPrefix(r.URL.RequestURI(), "/")]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(body))
+	}))
+	previous := os.Getenv("NODE_URL")
+	os.Setenv("NODE_URL", server.URL)
+	return func() {
+		server.Close()
+		os.Setenv("NODE_URL", previous)
+	}
+}
+
+// serveChain of blocks up to `head`, each baked by `baker`, on top of other
+// `routes`
+func serveChain(head int64, baker string, routes map[string]string) func() {
+	routes["monitor/bootstrapped"] = fmt.Sprintf(`{"block": "BL%v", "timestamp": "%v"}`, head, time.Now().Format(time.RFC3339))
+	for level := head - 10; level <= head; level++ {
+		routes[fmt.Sprintf("chains/main/blocks/BL%v~%v", head, head-level)] = fmt.Sprintf(`{
+			"hash": "BL%v",
+			"header": {"level": %v, "predecessor": "BL%v", "priority": 0},
+			"metadata": {"protocol": "PsQuebecnLByd3JwTiGadoG4nGWi3HYiLXUjkibeFV8dCFeVMUg", "baker": "%v"},
+			"operations": [[], [], [], []]
+		}`, level, level, level-1, baker)
+	}
+	return serveNode(routes)
+}
+
+// levelRecorder of the blocks it's handed
+type levelRecorder struct {
+	predecessor bool
+	levels      []int64
+}
+
+func (r *levelRecorder) Name() string {
+	return "recorder"
+}
+
+func (r *levelRecorder) AnalyzesPredecessor() bool {
+	return r.predecessor
+}
+
+func (r *levelRecorder) Analyze(b *BlockContext) {
+	r.levels = append(r.levels, b.Level)
+}
+
+func TestBackfill(t *testing.T) {
+	alert.SetDryRun(true)
+	defer serveChain(105, "tz1someone", map[string]string{})()
+
+	blocks, predecessors := &levelRecorder{}, &levelRecorder{predecessor: true}
+	m := Monitor{}
+	m.RegisterAnalyzers(blocks, predecessors)
+
+	// Endorsements of the last level are in the next block
+	m.Backfill(101, 103, 2)
+	if fmt.Sprint(blocks.levels) != "[101 102 103]" {
+		t.Error("Incorrect levels analyzed", blocks.levels)
+	}
+	if fmt.Sprint(predecessors.levels) != "[102 103 104]" {
+		t.Error("Incorrect blocks analyzed for their predecessor", predecessors.levels)
+	}
+
+	// The head's endorsements aren't included yet
+	blocks.levels, predecessors.levels = nil, nil
+	m.Backfill(104, 200, 2)
+	if fmt.Sprint(blocks.levels) != "[104]" || fmt.Sprint(predecessors.levels) != "[105]" {
+		t.Error("Expected backfill to stop before the head", blocks.levels, predecessors.levels)
+	}
+	if storage.GetLastRecordedBlockLevel() >= 101 {
+		t.Error("Expected backfill to leave the live cursor alone")
+	}
+}
@@ -0,0 +1,110 @@
+package monitor
+
+import (
+	"fmt"
+
+	"github.com/nlopes/slack"
+	"gitlab.com/polychainlabs/tezos-network-monitor/alert"
+	"gitlab.com/polychainlabs/tezos-network-monitor/logging"
+	"gitlab.com/polychainlabs/tezos-network-monitor/metrics"
+	"gitlab.com/polychainlabs/tezos-network-monitor/storage"
+	"gitlab.com/polychainlabs/tezos-network-monitor/tzrpc"
+)
+
+// CheckCapacity of these bakers to accept delegations.  Staking balance beyond
+// what a baker's own balance can cover in deposits earns nothing
+func (m *Monitor) CheckCapacity(bakers []string) {
+	logger := logging.Component("capacity")
+	head := m.getCurrentBlock()
+	constants, err := tzrpc.GetConstants(head.Hash())
+	if err != nil {
+		logger.WithError(err).Error("Unable to get constants")
+		return
+	}
+	networkStake, err := m.networkStake(head, constants)
+	if err != nil {
+		logger.WithError(err).Error("Unable to get the network's stake")
+		return
+	}
+	ratio := constants.CapacityRatio(networkStake)
+
+	for _, baker := range bakers {
+		delegate, err := tzrpc.GetDelegate(baker, head.Hash())
+		if err != nil {
+			logger.WithError(err).WithField("delegate", baker).Error("Unable to get delegate")
+			continue
+		}
+		m.updateCapacity(baker, head.Level(), delegate, ratio, constants.StakedCapacity())
+	}
+}
+
+// networkStake of every baker, from the rolls in the vote listings.  Only
+// needed before Tenderbake, when capacity depended on it
+func (m *Monitor) networkStake(head *tzrpc.Block, constants *tzrpc.Constants) (int64, error) {
+	if constants.Tenderbake() {
+		return 0, nil
+	}
+	listings, err := tzrpc.GetListings(head.Hash())
+	if err != nil {
+		return 0, err
+	}
+	rolls := int64(0)
+	for _, r := range listings {
+		rolls += r
+	}
+	return rolls * constants.TokensPerRoll, nil
+}
+
+// updateCapacity of a baker at the protocol's capacity `ratio`, unless
+// overridden, alerting when it starts approaching or goes over its capacity.
+// Only its frozen balance counts if `staked`
+func (m *Monitor) updateCapacity(baker string, level int64, delegate *tzrpc.Delegate, ratio float64, staked bool) storage.Capacity {
+	ratio = m.threshold("capacity", baker, "CapacityRatio", ratio)
+	own := delegate.Balance
+	if staked {
+		own = delegate.FrozenBalance
+	}
+	c := storage.Capacity{
+		Delegate:       baker,
+		Level:          level,
+		Balance:        delegate.Balance,
+		FrozenBalance:  delegate.FrozenBalance,
+		StakingBalance: delegate.StakingBalance,
+		Ratio:          ratio,
+		Capacity:       int64(float64(own) * ratio),
+		State:          storage.CapacityOK,
+	}
+	c.Headroom = c.Capacity - c.StakingBalance
+	if c.Capacity > 0 {
+		c.Utilization = float64(c.StakingBalance) / float64(c.Capacity)
+	}
+	switch {
+	case c.Headroom < 0:
+		c.State = storage.CapacityOver
+	case 100*c.Utilization >= m.threshold("capacity", baker, "SlackUtilizationPercent", 90):
+		c.State = storage.CapacityApproaching
+	}
+
+	previous, seen := storage.RecordCapacity(c)
+	metrics.StakingCapacity.WithLabelValues(baker).Set(float64(c.Capacity) / 1e6)
+	metrics.StakingHeadroom.WithLabelValues(baker).Set(float64(c.Headroom) / 1e6)
+	logging.Component("capacity").WithField("delegate", baker).WithField("level", level).
+		WithField("utilization", c.Utilization).Debug("Checked capacity")
+
+	if seen && previous.State == c.State {
+		return c
+	}
+	switch c.State {
+	case storage.CapacityOver:
+		alert.PostSlack(&slack.WebhookMessage{
+			Text: fmt.Sprintf("*Over Delegated* `%v` has a staking balance of `%vꜩ`, `%vꜩ` over its capacity of `%vꜩ`. Extra delegations earn nothing",
+				m.alias(baker), c.StakingBalance/1e6, -c.Headroom/1e6, c.Capacity/1e6),
+		})
+	case storage.CapacityApproaching:
+		alert.PostSlack(&slack.WebhookMessage{
+			Text: fmt.Sprintf("*Approaching Capacity* `%v` is at `%.1f%%` of its delegation capacity, with `%vꜩ` of headroom left",
+				m.alias(baker), 100*c.Utilization, c.Headroom/1e6),
+		})
+	}
+	return c
+}
@@ -0,0 +1,71 @@
+package monitor
+
+import (
+	"testing"
+
+	"gitlab.com/polychainlabs/tezos-network-monitor/storage"
+	"gitlab.com/polychainlabs/tezos-network-monitor/tzrpc"
+)
+
+func TestUpdateCapacity(t *testing.T) {
+	alerts := captureAlerts()
+	m := Monitor{}
+	// 10000ꜩ at a ratio of 12 can cover 120000ꜩ
+	tests := []struct {
+		stakingBalance int64
+		state          string
+		headroom       int64
+		alert          string
+	}{
+		{60000e6, storage.CapacityOK, 60000e6, ""},
+		{107999e6, storage.CapacityOK, 12001e6, ""},
+		{108000e6, storage.CapacityApproaching, 12000e6, "*Approaching Capacity* `tz1cap...` is at `90.0%`"},
+		{120000e6, storage.CapacityApproaching, 0, ""},
+		{120000e6 + 1, storage.CapacityOver, -1, "*Over Delegated*"},
+		{140000e6, storage.CapacityOver, -20000e6, ""},
+		{120000e6, storage.CapacityApproaching, 0, "is at `100.0%` of its delegation capacity, with `0ꜩ` of headroom left"},
+	}
+	for _, test := range tests {
+		before := len(alerts())
+		c := m.updateCapacity("tz1capacity", 1, &tzrpc.Delegate{Balance: 10000e6, StakingBalance: test.stakingBalance}, 12, false)
+		if c.State != test.state || c.Headroom != test.headroom {
+			t.Errorf("Expected %v with %v headroom for %v staked but found %v with %v", test.state, test.headroom, test.stakingBalance, c.State, c.Headroom)
+		}
+
+		// Only alerts on state transitions, and exactly at capacity isn't over
+		sent := alerts()[before:]
+		if len(test.alert) == 0 && len(sent) != 0 || len(test.alert) > 0 && countAlerts(sent, "slack", test.alert) != 1 {
+			t.Errorf("Expected alert %q for %v staked but found %v", test.alert, test.stakingBalance, sent)
+		}
+	}
+}
+
+func TestCapacityRatio(t *testing.T) {
+	captureAlerts()
+	// Babylon froze deposits for every right over 6 cycles, out of a 600Mꜩ
+	// network stake
+	babylon := &tzrpc.Constants{PreservedCycles: 5, BlocksPerCycle: 4096, EndorsersPerBlock: 32,
+		BlockSecurityDeposit: 512e6, EndorsementSecurityDeposit: 64e6, TokensPerRoll: 8000e6}
+	if r := babylon.CapacityRatio(600e12); r < 9.5 || r > 9.6 {
+		t.Error("Incorrect Babylon capacity ratio", r)
+	}
+	if r := (&tzrpc.Constants{FrozenDepositsPercentage: 10}).CapacityRatio(0); r != 10 {
+		t.Error("Incorrect Ithaca capacity ratio", r)
+	}
+	oxford := &tzrpc.Constants{LimitOfDelegationOverBaking: 9}
+	if r := oxford.CapacityRatio(0); r != 10 || !oxford.StakedCapacity() {
+		t.Error("Incorrect Oxford capacity ratio", r)
+	}
+
+	// Only the staked balance counts since Oxford, unless overridden
+	m := Monitor{config: map[string]CheckConfig{"capacity": {Delegates: map[string]map[string]float64{
+		"tz1override": {"CapacityRatio": 5},
+	}}}}
+	delegate := &tzrpc.Delegate{Balance: 10000e6, FrozenBalance: 4000e6, StakingBalance: 30000e6}
+	if c := m.updateCapacity("tz1staked", 1, delegate, oxford.CapacityRatio(0), oxford.StakedCapacity()); c.Capacity != 40000e6 {
+		t.Error("Expected capacity from the staked balance, found", c.Capacity)
+	}
+	if c := m.updateCapacity("tz1override", 1, delegate, oxford.CapacityRatio(0), false); c.Capacity != 50000e6 || c.Ratio != 5 {
+		t.Error("Expected the configured ratio, found", c.Ratio)
+	}
+}
@@ -0,0 +1,96 @@
+package monitor
+
+// Check run on every iteration of the monitor
+type Check interface {
+	// Name used to configure the check
+	Name() string
+	// Run the check once
+	Run()
+}
+
+// CheckConfig enabling or disabling a check or analyzer, and overriding its
+// thresholds for every delegate or for specific delegates
+type CheckConfig struct {
+	Enabled    *bool                         `yaml:"Enabled"`
+	Thresholds map[string]float64            `yaml:"Thresholds"`
+	Delegates  map[string]map[string]float64 `yaml:"Delegates"`
+}
+
+type checkFunc struct {
+	name string
+	run  func()
+}
+
+func (c checkFunc) Name() string {
+	return c.name
+}
+
+func (c checkFunc) Run() {
+	c.run()
+}
+
+// NewCheck running `run` under `name`
+func NewCheck(name string, run func()) Check {
+	return checkFunc{name: name, run: run}
+}
+
+// DefaultChecks for our addresses and these bakers
+func (m *Monitor) DefaultChecks(bakers []string, workers int) []Check {
+	return []Check{
+		NewCheck("node", m.CheckNode),
+		NewCheck("consensus_keys", func() { m.CheckConsensusKeys(bakers) }),
+		NewCheck("blocks", func() { m.CheckBlocks(workers) }),
+		NewCheck("mempool", func() { m.CheckMempool(bakers) }),
+		NewCheck("balances", func() { m.CheckBalances(bakers, workers) }),
+		NewCheck("deactivation", func() { m.CheckDeactivation(bakers) }),
+		NewCheck("capacity", func() { m.CheckCapacity(bakers) }),
+		NewCheck("forecast", func() { m.CheckForecast(bakers) }),
+		NewCheck("delegators", func() { m.CheckDelegators(bakers) }),
+		NewCheck("governance", func() { m.CheckGovernance(bakers) }),
+		NewCheck("protocol", m.CheckProtocol),
+	}
+}
+
+// RegisterChecks to run on every iteration, in order
+func (m *Monitor) RegisterChecks(checks ...Check) {
+	m.checks = append(m.checks, checks...)
+}
+
+// Configure checks and analyzers by name
+func (m *Monitor) Configure(config map[string]CheckConfig) {
+	m.config = config
+}
+
+// RunChecks that are enabled, in order
+func (m *Monitor) RunChecks() {
+	for _, check := range m.checks {
+		if m.enabled(check.Name()) {
+			check.Run()
+		}
+	}
+}
+
+// enabled unless configured otherwise
+func (m *Monitor) enabled(name string) bool {
+	config, ok := m.config[name]
+	if !ok || config.Enabled == nil {
+		return true
+	}
+	return *config.Enabled
+}
+
+// threshold `key` of check `name` for `delegate`, preferring the delegate's
+// own threshold, then the check's, then `defaultValue`
+func (m *Monitor) threshold(name string, delegate string, key string, defaultValue float64) float64 {
+	config, ok := m.config[name]
+	if !ok {
+		return defaultValue
+	}
+	if value, ok := config.Delegates[delegate][key]; ok {
+		return value
+	}
+	if value, ok := config.Thresholds[key]; ok {
+		return value
+	}
+	return defaultValue
+}
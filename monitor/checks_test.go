@@ -0,0 +1,35 @@
+package monitor
+
+import (
+	"testing"
+)
+
+func TestThresholds(t *testing.T) {
+	disabled := false
+	m := Monitor{}
+	m.Configure(map[string]CheckConfig{
+		"baking": CheckConfig{
+			Thresholds: map[string]float64{"PageCycleMisses": 4},
+			Delegates: map[string]map[string]float64{
+				"tz1a": map[string]float64{"PageCycleMisses": 8},
+			},
+		},
+		"node": CheckConfig{Enabled: &disabled},
+	})
+
+	if m.threshold("baking", "tz1a", "PageCycleMisses", 2) != 8 {
+		t.Error("Expected the delegate's own threshold")
+	}
+	if m.threshold("baking", "tz1b", "PageCycleMisses", 2) != 4 {
+		t.Error("Expected the check's threshold")
+	}
+	if m.threshold("endorsing", "tz1a", "PageCycleMisses", 5) != 5 {
+		t.Error("Expected the default threshold")
+	}
+	if m.enabled("node") {
+		t.Error("Expected node check to be disabled")
+	}
+	if !m.enabled("blocks") {
+		t.Error("Expected unconfigured checks to be enabled")
+	}
+}
@@ -0,0 +1,102 @@
+package monitor
+
+import (
+	"fmt"
+
+	"github.com/nlopes/slack"
+	"github.com/sirupsen/logrus"
+	"gitlab.com/polychainlabs/tezos-network-monitor/alert"
+	"gitlab.com/polychainlabs/tezos-network-monitor/logging"
+	"gitlab.com/polychainlabs/tezos-network-monitor/metrics"
+	"gitlab.com/polychainlabs/tezos-network-monitor/storage"
+	"gitlab.com/polychainlabs/tezos-network-monitor/tzrpc"
+)
+
+// CheckConsensusKeys of these bakers, so rights and operations signed with
+// them are attributed to the baker
+func (m *Monitor) CheckConsensusKeys(bakers []string) {
+	head := m.getCurrentBlock()
+	for _, baker := range bakers {
+		keys, err := tzrpc.GetConsensusKeys(baker, head.Hash())
+		if err != nil {
+			logging.Component("consensus_keys").WithError(err).WithField("delegate", baker).Error("Unable to get consensus keys")
+			continue
+		}
+		m.updateConsensusKeys(baker, *keys)
+	}
+}
+
+// updateConsensusKeys of a baker, alerting when a new key becomes active
+func (m *Monitor) updateConsensusKeys(baker string, keys tzrpc.ConsensusKeys) {
+	tzrpc.RegisterConsensusKeys(baker, keys.Keys())
+	previous, seen := storage.RecordConsensusKeys(baker, keys)
+	if !seen || previous.Active == keys.Active {
+		return
+	}
+
+	logging.Component("consensus_keys").WithFields(logrus.Fields{
+		"delegate": baker,
+		"previous": previous.Active,
+		"active":   keys.Active,
+	}).Warn("Consensus key activated")
+	alert.PostSlack(&slack.WebhookMessage{
+		Text: fmt.Sprintf("*Consensus Key Activated* `%v` now signs with `%v`, replacing `%v`", m.alias(baker), keys.Active, previous.Active),
+	})
+}
+
+// consensusKeyAnalyzer alerts on consensus key updates by our bakers, and
+// pages if one of them is drained
+type consensusKeyAnalyzer struct {
+	*Monitor
+	bakers []string
+}
+
+func (a consensusKeyAnalyzer) Name() string {
+	return "consensus_keys"
+}
+
+func (a consensusKeyAnalyzer) Analyze(b *BlockContext) {
+	m := a.Monitor
+	for _, u := range b.Block.ConsensusKeyUpdates() {
+		if !contains(a.bakers, u.Source) {
+			continue
+		}
+		b.Logger.WithFields(logrus.Fields{
+			"delegate":   u.Source,
+			"public_key": u.PublicKey,
+			"status":     u.Status,
+		}).Warn("Consensus key update")
+		alert.PostSlack(&slack.WebhookMessage{
+			Text: fmt.Sprintf("*Consensus Key Update* by `%v` to `%v` at level `%v` (`%v`). It activates after the preserved cycles",
+				m.alias(u.Source), u.PublicKey, b.Level, u.Status),
+		})
+	}
+
+	for _, d := range b.Block.Drains() {
+		delegate := tzrpc.ResolveDelegate(d.Delegate)
+		if !contains(a.bakers, delegate) {
+			continue
+		}
+		m.drained(b, delegate, d)
+	}
+}
+
+// drained `delegate`'s balance, which only its consensus key can sign for
+func (m *Monitor) drained(b *BlockContext, delegate string, d tzrpc.Drain) {
+	b.Logger.WithFields(logrus.Fields{
+		"delegate":      delegate,
+		"consensus_key": d.ConsensusKey,
+		"destination":   d.Destination,
+		"amount":        d.Amount,
+	}).Error("Delegate drained")
+	metrics.DelegateDrains.WithLabelValues(delegate).Inc()
+
+	alert.PostSlack(&slack.WebhookMessage{
+		Text: fmt.Sprintf("*DELEGATE DRAINED* `%v` by consensus key `%v` at level `%v`. `%vꜩ` sent to `%v`",
+			m.alias(delegate), d.ConsensusKey, b.Level, float64(d.Amount)/1e6, m.alias(d.Destination)),
+	})
+	title := fmt.Sprintf("Baker %v drained by its consensus key", m.alias(delegate))
+	body := fmt.Sprintf("%vꜩ was sent from %v to %v at level %v, signed by %v.  If this wasn't us, the consensus key is compromised.",
+		float64(d.Amount)/1e6, delegate, d.Destination, b.Level, d.ConsensusKey)
+	alert.Page(title, body)
+}
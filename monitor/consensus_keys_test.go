@@ -0,0 +1,32 @@
+package monitor
+
+import (
+	"testing"
+
+	"gitlab.com/polychainlabs/tezos-network-monitor/alert"
+	"gitlab.com/polychainlabs/tezos-network-monitor/storage"
+	"gitlab.com/polychainlabs/tezos-network-monitor/tzrpc"
+)
+
+func TestUpdateConsensusKeys(t *testing.T) {
+	alert.SetDryRun(true)
+	m := Monitor{}
+	baker := "tz1consensusbaker"
+
+	m.updateConsensusKeys(baker, tzrpc.ConsensusKeys{
+		Active:  baker,
+		Pending: []tzrpc.PendingConsensusKey{{Cycle: 700, PKH: "tz1consensuskey"}},
+	})
+	if tzrpc.ResolveDelegate("tz1consensuskey") != baker {
+		t.Error("Expected the pending key to be attributed to the baker")
+	}
+
+	m.updateConsensusKeys(baker, tzrpc.ConsensusKeys{Active: "tz1consensuskey"})
+	keys, ok := storage.GetConsensusKeys(baker)
+	if !ok || keys.Active != "tz1consensuskey" || len(keys.Pending) != 0 {
+		t.Errorf("Incorrect recorded keys %+v", keys)
+	}
+	if tzrpc.ResolveDelegate("tz1consensuskey") != baker {
+		t.Error("Expected the active key to stay attributed to the baker")
+	}
+}
@@ -0,0 +1,59 @@
+package monitor
+
+import (
+	"fmt"
+
+	"github.com/nlopes/slack"
+	"gitlab.com/polychainlabs/tezos-network-monitor/alert"
+	"gitlab.com/polychainlabs/tezos-network-monitor/logging"
+	"gitlab.com/polychainlabs/tezos-network-monitor/metrics"
+	"gitlab.com/polychainlabs/tezos-network-monitor/storage"
+	"gitlab.com/polychainlabs/tezos-network-monitor/tzrpc"
+)
+
+// CheckDeactivation of these bakers, which are deactivated along with all of
+// their delegations once their grace period passes without baking or
+// endorsing
+func (m *Monitor) CheckDeactivation(bakers []string) {
+	head := m.getCurrentBlock()
+	currentCycle := tzrpc.Cycle(head.Level())
+	for _, baker := range bakers {
+		delegate, err := tzrpc.GetDelegate(baker, head.Hash())
+		if err != nil {
+			logging.Component("deactivation").WithError(err).WithField("delegate", baker).Error("Unable to get delegate")
+			continue
+		}
+		m.updateActivity(baker, currentCycle, delegate.Deactivated, delegate.GracePeriod)
+	}
+}
+
+// updateActivity of a baker, alerting when it's deactivated or reactivated,
+// and once a cycle while close to deactivation
+func (m *Monitor) updateActivity(baker string, currentCycle int64, deactivated bool, gracePeriod int64) {
+	previous, seen := storage.RecordActivity(baker, currentCycle, deactivated, gracePeriod)
+	cyclesLeft := gracePeriod - currentCycle
+	metrics.CyclesUntilDeactivation.WithLabelValues(baker).Set(float64(cyclesLeft))
+	logger := logging.Component("deactivation").WithField("delegate", baker).WithField("cycle", currentCycle)
+
+	switch {
+	case deactivated && !(seen && previous.Deactivated):
+		logger.Error("Baker deactivated")
+		alert.PostSlack(&slack.WebhookMessage{
+			Text: fmt.Sprintf("*Baker Deactivated* `%v` in cycle `%v`. Its delegations no longer count towards rights", m.alias(baker), currentCycle),
+		})
+		title := fmt.Sprintf("Baker %v deactivated", m.alias(baker))
+		body := fmt.Sprintf("%v was deactivated after its grace period ended in cycle %v.  Re-register it as a delegate to reactivate it.", baker, gracePeriod)
+		alert.Page(title, body)
+	case !deactivated && seen && previous.Deactivated:
+		logger.Info("Baker reactivated")
+		alert.PostSlack(&slack.WebhookMessage{
+			Text: fmt.Sprintf("*Baker Reactivated* `%v` in cycle `%v`", m.alias(baker), currentCycle),
+		})
+	case !deactivated && float64(cyclesLeft) <= m.threshold("deactivation", baker, "SlackCyclesLeft", 2) && previous.WarnedCycle != currentCycle:
+		logger.WithField("cycles_left", cyclesLeft).Warn("Baker close to deactivation")
+		storage.MarkActivityWarned(baker, currentCycle)
+		alert.PostSlack(&slack.WebhookMessage{
+			Text: fmt.Sprintf("*Deactivation Risk* `%v` will be deactivated after cycle `%v`, `%v` cycles from now, unless it bakes or endorses", m.alias(baker), gracePeriod, cyclesLeft),
+		})
+	}
+}
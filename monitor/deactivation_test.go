@@ -0,0 +1,45 @@
+package monitor
+
+import (
+	"testing"
+
+	"gitlab.com/polychainlabs/tezos-network-monitor/storage"
+)
+
+func TestUpdateActivity(t *testing.T) {
+	alerts := captureAlerts()
+	m := Monitor{}
+
+	// One cycle more than the threshold
+	m.updateActivity("tz1deactivation", 203, false, 206)
+	if a, _ := storage.GetActivity("tz1deactivation"); a.WarnedCycle != -1 || len(alerts()) != 0 {
+		t.Error("Expected no warning 3 cycles from deactivation", alerts())
+	}
+
+	// Exactly the threshold warns, once a cycle
+	m.updateActivity("tz1deactivation", 204, false, 206)
+	m.updateActivity("tz1deactivation", 204, false, 206)
+	if a, _ := storage.GetActivity("tz1deactivation"); a.WarnedCycle != 204 ||
+		countAlerts(alerts(), "slack", "will be deactivated after cycle `206`, `2` cycles from now") != 1 {
+		t.Error("Expected a single warning 2 cycles from deactivation", alerts())
+	}
+
+	// The grace period's last cycle is still active
+	m.updateActivity("tz1deactivation", 206, false, 206)
+	if a, _ := storage.GetActivity("tz1deactivation"); a.Deactivated || a.WarnedCycle != 206 ||
+		countAlerts(alerts(), "slack", "`0` cycles from now") != 1 || countAlerts(alerts(), "pagerduty", "deactivated") != 0 {
+		t.Error("Expected a warning, not a page, in the last cycle of the grace period", alerts())
+	}
+
+	// Deactivated the cycle after, then reactivated
+	m.updateActivity("tz1deactivation", 207, true, 206)
+	m.updateActivity("tz1deactivation", 207, true, 206)
+	if a, _ := storage.GetActivity("tz1deactivation"); !a.Deactivated || countAlerts(alerts(), "pagerduty", "deactivated") != 1 {
+		t.Error("Expected the baker to be recorded as deactivated once", alerts())
+	}
+	m.updateActivity("tz1deactivation", 208, false, 214)
+	if a, _ := storage.GetActivity("tz1deactivation"); a.Deactivated || a.GracePeriod != 214 ||
+		countAlerts(alerts(), "slack", "*Baker Reactivated*") != 1 || countAlerts(alerts(), "slack", "*Deactivation Risk*") != 2 {
+		t.Error("Expected the baker to be recorded as reactivated", alerts())
+	}
+}
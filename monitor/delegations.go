@@ -0,0 +1,80 @@
+package monitor
+
+import (
+	"fmt"
+
+	"github.com/nlopes/slack"
+	"gitlab.com/polychainlabs/tezos-network-monitor/alert"
+	"gitlab.com/polychainlabs/tezos-network-monitor/logging"
+	"gitlab.com/polychainlabs/tezos-network-monitor/tzrpc"
+)
+
+// delegationAnalyzer alerts when delegations are received or made
+type delegationAnalyzer struct {
+	*Monitor
+}
+
+func (a delegationAnalyzer) Name() string {
+	return "delegations"
+}
+
+func (a delegationAnalyzer) Analyze(b *BlockContext) {
+	m := a.Monitor
+	for _, delegation := range b.Block.Delegations() {
+		for _, address := range m.addresses {
+			if address == delegation.Delegate {
+				amount := getBalanceString(delegation.Source)
+				alert.PostSlack(&slack.WebhookMessage{
+					Text: fmt.Sprintf("*Delegation* `%v` delegated `%vꜩ` to `%v`",
+						m.alias(delegation.Source), amount, m.alias(delegation.Delegate)),
+				})
+			}
+			if address == delegation.Source {
+				amount := getBalanceString(delegation.Source)
+				alert.PostSlack(&slack.WebhookMessage{
+					Text: fmt.Sprintf("*Delegation* We delegated `%vꜩ`from `%v` to `%v`",
+						amount, m.alias(delegation.Source), m.alias(delegation.Delegate)),
+				})
+			}
+		}
+	}
+}
+
+// originationAnalyzer alerts when delegations are received through
+// originations, or we originate a contract
+type originationAnalyzer struct {
+	*Monitor
+}
+
+func (a originationAnalyzer) Name() string {
+	return "originations"
+}
+
+func (a originationAnalyzer) Analyze(b *BlockContext) {
+	m := a.Monitor
+	for _, origination := range b.Block.Originations() {
+		for _, address := range m.addresses {
+			if address == origination.Delegate {
+				alert.PostSlack(&slack.WebhookMessage{
+					Text: fmt.Sprintf("*Delegation* `%v` delegated `%vꜩ` to `%v` through an origination",
+						origination.Source, origination.Balance.String(), m.alias(origination.Delegate)),
+				})
+			}
+			if address == origination.Source {
+				alert.PostSlack(&slack.WebhookMessage{
+					Text: fmt.Sprintf("*Origination* We originated a contract from `%v`",
+						m.alias(origination.Source)),
+				})
+			}
+		}
+	}
+}
+
+func getBalanceString(pkh string) string {
+	balance, err := tzrpc.GetBalance(pkh)
+	if err != nil {
+		logging.Component("delegations").WithError(err).WithField("address", pkh).Error("Unable to get balance")
+		return ""
+	}
+	return balance.String()
+}
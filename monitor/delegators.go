@@ -0,0 +1,74 @@
+package monitor
+
+import (
+	"fmt"
+
+	"github.com/nlopes/slack"
+	"github.com/sirupsen/logrus"
+	"gitlab.com/polychainlabs/tezos-network-monitor/alert"
+	"gitlab.com/polychainlabs/tezos-network-monitor/logging"
+	"gitlab.com/polychainlabs/tezos-network-monitor/metrics"
+	"gitlab.com/polychainlabs/tezos-network-monitor/storage"
+	"gitlab.com/polychainlabs/tezos-network-monitor/tzrpc"
+)
+
+// CheckDelegators of these bakers, recording every contract that joins or
+// leaves with its balance, and alerting on whales
+func (m *Monitor) CheckDelegators(bakers []string) {
+	head := m.getCurrentBlock()
+	for _, baker := range bakers {
+		contracts, err := tzrpc.GetDelegatedContracts(baker, head.Hash())
+		if err != nil {
+			logging.Component("delegators").WithError(err).WithField("delegate", baker).Error("Unable to get delegated contracts")
+			continue
+		}
+		added, removed := storage.RecordDelegators(baker, contracts)
+		metrics.Delegators.WithLabelValues(baker).Set(float64(len(contracts)))
+
+		for _, contract := range added {
+			m.updateDelegation(baker, contract, head, true)
+		}
+		for _, contract := range removed {
+			m.updateDelegation(baker, contract, head, false)
+		}
+	}
+}
+
+// updateDelegation of `contract` joining or leaving `baker`
+func (m *Monitor) updateDelegation(baker string, contract string, head *tzrpc.Block, added bool) {
+	balance := int64(0)
+	if b, err := tzrpc.GetBalanceAt(contract, head.Hash()); err == nil {
+		balance = b.Int64()
+	}
+	m.recordDelegation(baker, contract, head.Level(), balance, added)
+}
+
+// recordDelegation of `balance` tez joining or leaving `baker`, alerting if
+// it's more than the whale threshold
+func (m *Monitor) recordDelegation(baker string, contract string, level int64, balance int64, added bool) {
+	storage.RecordDelegationChange(baker, contract, level, balance, added)
+	change := balance
+	if !added {
+		change = -balance
+	}
+	metrics.DelegationChange.WithLabelValues(baker, cycle(level)).Add(float64(change))
+	logging.Component("delegators").WithFields(logrus.Fields{
+		"delegate": baker,
+		"contract": contract,
+		"balance":  balance,
+		"added":    added,
+	}).Info("Delegation changed")
+
+	if float64(balance) < m.threshold("delegators", baker, "SlackWhaleTez", 10000) {
+		return
+	}
+	if added {
+		alert.PostSlack(&slack.WebhookMessage{
+			Text: fmt.Sprintf("*Whale Delegation* `%v` delegated `%vꜩ` to `%v`", m.alias(contract), balance, m.alias(baker)),
+		})
+	} else {
+		alert.PostSlack(&slack.WebhookMessage{
+			Text: fmt.Sprintf("*Whale Departure* `%v` took `%vꜩ` away from `%v`", m.alias(contract), balance, m.alias(baker)),
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package monitor
+
+import (
+	"testing"
+
+	"gitlab.com/polychainlabs/tezos-network-monitor/alert"
+	"gitlab.com/polychainlabs/tezos-network-monitor/storage"
+	"gitlab.com/polychainlabs/tezos-network-monitor/tzrpc"
+)
+
+func TestDelegators(t *testing.T) {
+	alert.SetDryRun(true)
+	m := Monitor{}
+	first, _ := tzrpc.CycleLevels(200)
+
+	// First sight is a baseline
+	if added, removed := storage.RecordDelegators("tz1delegators", []string{"tz1a", "KT1b"}); len(added)+len(removed) != 0 {
+		t.Error("Expected no changes on first sight")
+	}
+
+	added, removed := storage.RecordDelegators("tz1delegators", []string{"tz1a", "KT1c"})
+	if len(added) != 1 || added[0] != "KT1c" || len(removed) != 1 || removed[0] != "KT1b" {
+		t.Error("Incorrect changes", added, removed)
+	}
+	m.recordDelegation("tz1delegators", "KT1c", first, 50000, true)
+	m.recordDelegation("tz1delegators", "KT1b", first+1, 20000, false)
+
+	if net := storage.GetNetDelegationChange("tz1delegators", 200); net != 30000 {
+		t.Error("Expected a net change of 30000 but found", net)
+	}
+}
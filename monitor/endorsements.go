@@ -2,71 +2,111 @@ package monitor
 
 import (
 	"fmt"
-	"log"
 
 	"github.com/nlopes/slack"
+	"github.com/sirupsen/logrus"
 	"gitlab.com/polychainlabs/tezos-network-monitor/alert"
+	"gitlab.com/polychainlabs/tezos-network-monitor/metrics"
 	"gitlab.com/polychainlabs/tezos-network-monitor/storage"
 	"gitlab.com/polychainlabs/tezos-network-monitor/tzrpc"
 )
 
-// CheckEndorsing performance for this delegate
-func (m *Monitor) CheckEndorsing(delegate string) {
-	currentBlock := m.getCurrentBlock()
+// endorsingAnalyzer of each baker's performance.  Endorsements for level `n`
+// are included in block `n+1`, so each block is used to analyze its
+// predecessor's level
+type endorsingAnalyzer struct {
+	*Monitor
+	bakers  []string
+	workers int
+}
 
-	// Get last checked level from firestore
-	lastRecordedLevel := storage.GetLastRecordedEndorsementLevel(delegate)
+func (a endorsingAnalyzer) Name() string {
+	return "endorsing"
+}
 
-	// Endorsing rights are only availabe so many levels behind, so start no earlier than 1 cycle ago
-	if lastRecordedLevel == -1 || lastRecordedLevel < currentBlock.Level()-4096 {
-		minLevel := currentBlock.Level() - 2
-		m.logError(fmt.Errorf("[Endorsing]\tLast recorded level of %v is too low.  Resetting to %v",
-			lastRecordedLevel, minLevel))
-		lastRecordedLevel = minLevel
-	}
+func (a endorsingAnalyzer) AnalyzesPredecessor() bool {
+	return true
+}
 
-	// Analyze endoring rights
-	for level := lastRecordedLevel + 1; level < currentBlock.Level(); level++ {
-		debugf("[Endorsing]\tAnalyzing level %v for %v\n", level, delegate)
-		// Get rights at level
-		endorsingRights, err := tzrpc.GetEndorsingRights(level)
-		m.check(err)
-		rights := endorsingRights.Slots(delegate)
-
-		// Get endorsements from block `n-1`
-		block, err := tzrpc.GetBlock(currentBlock.Hash(), currentBlock.Level()-level-1)
-		m.check(err)
-		endorsements, err := block.Endorsements(delegate)
-		m.check(err)
-
-		// Get block hash from block `n`
-		block, err = tzrpc.GetBlock(currentBlock.Hash(), currentBlock.Level()-level)
-		m.check(err)
-		hash := block.Hash()
-
-		// Alert on misses
-		if len(rights) > len(endorsements) {
-			alert.PostSlack(&slack.WebhookMessage{
-				Text: fmt.Sprintf("*Missed Endorsement* at level `%v` with baker `%v`",
-					level, m.alias(delegate)),
-			})
-
-			// Page if we've missed a lot this cycle
-			m.checkEndorsingTrends(delegate)
-		}
+func (a endorsingAnalyzer) Analyze(b *BlockContext) {
+	a.analyzeDelegates(b, a.bakers, a.workers, a.analyzeDelegate)
+}
 
-		// Machine parseable logline
-		log.Printf("endorser=%v endorsements=%v misses=%v level=%v\n",
-			delegate, len(endorsements), len(rights)-len(endorsements), level)
+// analyzeDelegate endorsements of the level before this block
+func (a endorsingAnalyzer) analyzeDelegate(b *BlockContext, delegate string) {
+	m := a.Monitor
+	level := b.Level - 1
+
+	// Get rights at level
+	rights, err := tzrpc.EndorsingSlots(level, delegate)
+	m.check(err)
+
+	// Get endorsements from block `n+1`
+	endorsements, err := b.Block.Endorsements(delegate)
+	m.check(err)
+
+	// Block hash of block `n`
+	hash := b.Block.Predecessor()
+
+	// Save to Datastore
+	storage.RecordEndorsement(delegate, level, tzrpc.Cycle(level), rights, endorsements, hash)
+	m.recordInclusions(b, delegate)
+
+	// Alert on misses, with what the mempool saw of them
+	reason := ""
+	if len(rights) > len(endorsements) {
+		reason = storage.MissReason(delegate, level)
+		metrics.EndorsementMisses.WithLabelValues(delegate, reason).Inc()
+		alert.PostSlack(&slack.WebhookMessage{
+			Text: fmt.Sprintf("*Missed Endorsement* at level `%v` with baker `%v`: %v",
+				level, m.alias(delegate), reason),
+		})
+
+		// Page if we've missed a lot this cycle
+		m.checkEndorsingTrends(delegate)
+	}
 
-		// Save to Datastore
-		storage.RecordEndorsement(delegate, level, rights, endorsements, hash)
+	// Machine parseable logline
+	withLevel(b.Logger, level).WithFields(logrus.Fields{
+		"delegate":     delegate,
+		"block_hash":   hash,
+		"endorsements": len(endorsements),
+		"misses":       len(rights) - len(endorsements),
+		"reason":       reason,
+	}).Info("Endorsed level")
+	metrics.EndorsementSlotsMade.WithLabelValues(delegate, cycle(level)).Add(float64(len(endorsements)))
+	if len(rights) > len(endorsements) {
+		metrics.EndorsementSlotsMissed.WithLabelValues(delegate, cycle(level)).Add(float64(len(rights) - len(endorsements)))
+	}
+	metrics.LastProcessedLevel.WithLabelValues("endorsing", delegate).Set(float64(level))
+}
+
+// recordInclusions of the delegate's endorsements in this block.  One included
+// after the next block was already alerted on as missed, so is only reported
+// as late
+func (m *Monitor) recordInclusions(b *BlockContext, delegate string) {
+	baker := b.Block.Baker()
+	for _, endorsed := range b.Block.EndorsedLevels(delegate) {
+		if !storage.RecordInclusion(delegate, endorsed, b.Level, baker) || endorsed >= b.Level-1 {
+			continue
+		}
+		latency := b.Level - endorsed
+		withLevel(b.Logger, endorsed).WithFields(logrus.Fields{
+			"delegate":    delegate,
+			"included_at": b.Level,
+			"included_by": baker,
+			"latency":     latency,
+		}).Warn("Endorsement included late")
+		alert.PostSlack(&slack.WebhookMessage{
+			Text: fmt.Sprintf("*Late Endorsement* at level `%v` with baker `%v`, reported missed, was included at level `%v` by `%v`, `%v` levels later",
+				endorsed, m.alias(delegate), b.Level, m.alias(baker), latency),
+		})
 	}
 }
 
 // checkEndorsingTrends and alert if we're missing a lot
 func (m *Monitor) checkEndorsingTrends(delegate string) {
-	previousLevels := 20
+	previousLevels := int(m.threshold("endorsing", delegate, "WindowLevels", 20))
 	endorsements := storage.GetEndorsements(delegate, previousLevels)
 
 	nMisses := 0
@@ -79,7 +119,7 @@ func (m *Monitor) checkEndorsingTrends(delegate string) {
 	}
 
 	// Page if miss 2 or more of last `previousLevels` endorsements
-	if nMisses >= 2 {
+	if float64(nMisses) >= m.threshold("endorsing", delegate, "PageWindowMisses", 2) {
 		title := fmt.Sprintf("Missed %v of last %v endorsements for %v",
 			nMisses, previousLevels, delegate)
 		body := fmt.Sprintf("Is this baker online? From level %v", level)
@@ -89,7 +129,7 @@ func (m *Monitor) checkEndorsingTrends(delegate string) {
 	// Cycle Misses
 	cycleMisses := storage.GetCycleEndorsementMissCount(delegate)
 	// Page if miss more than 5 per cycle
-	if cycleMisses > 5 {
+	if float64(cycleMisses) > m.threshold("endorsing", delegate, "PageCycleMisses", 5) {
 		title := fmt.Sprintf("Missed many endorsements by %v", delegate)
 		body := fmt.Sprintf("Missed %v endorsements.  Is this baker online?", cycleMisses)
 		alert.Page(title, body)
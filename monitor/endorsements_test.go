@@ -0,0 +1,98 @@
+package monitor
+
+import (
+	"fmt"
+	"testing"
+
+	"gitlab.com/polychainlabs/tezos-network-monitor/logging"
+	"gitlab.com/polychainlabs/tezos-network-monitor/storage"
+	"gitlab.com/polychainlabs/tezos-network-monitor/tzrpc"
+)
+
+// endorsingBlock at `level` with `contents` in its consensus operations
+func endorsingBlock(level int64, contents string) string {
+	return fmt.Sprintf(`{
+		"hash": "BL%v",
+		"header": {"level": %v, "predecessor": "BL%v"},
+		"metadata": {"protocol": "PsQuebecnLByd3JwTiGadoG4nGWi3HYiLXUjkibeFV8dCFeVMUg", "baker": "tz1inclusionbaker"},
+		"operations": [[{"contents": [%v]}], [], [], []]
+	}`, level, level, level-1, contents)
+}
+
+func TestAnalyzeEndorsementMisses(t *testing.T) {
+	alerts := captureAlerts()
+	delegate := "tz1inclusion"
+	routes := map[string]string{
+		"chains/main/blocks/BL5001~0": endorsingBlock(5001, ""),
+		"chains/main/blocks/BL5002~0": endorsingBlock(5002, ""),
+		"chains/main/blocks/BL5003~0": endorsingBlock(5003, ""),
+		"chains/main/blocks/BL5004~0": endorsingBlock(5004,
+			`{"kind": "endorsement", "level": 5002, "metadata": {"delegate": "tz1inclusion", "slots": [4]}}`),
+	}
+	rights := map[int64]string{
+		5000: `[{"level": 5000, "delegate": "tz1inclusion", "slots": [1]}]`,
+		5001: `[{"level": 5001, "delegate": "tz1inclusion", "slots": [2]}, {"level": 5001, "delegate": "tz1someone", "slots": [9]}]`,
+		5002: `[{"level": 5002, "delegate": "tz1inclusion", "slots": [4]}]`,
+		5003: `[]`,
+	}
+	for level, r := range rights {
+		routes[fmt.Sprintf("chains/main/blocks/%v/helpers/endorsing_rights?level=%v", level, level)] = r
+		routes[fmt.Sprintf("chains/main/blocks/head/helpers/endorsing_rights?level=%v", level)] = r
+	}
+	defer serveNode(routes)()
+
+	a := endorsingAnalyzer{&Monitor{}, []string{delegate}, 1}
+	blocks := map[int64]*tzrpc.Block{}
+	for level := int64(5001); level <= 5004; level++ {
+		block, err := tzrpc.GetBlock(fmt.Sprintf("BL%v", level), 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		blocks[level] = block
+	}
+	analyze := func(level int64) []string {
+		before := len(alerts())
+		a.analyzeDelegate(&BlockContext{Level: level, Block: blocks[level], Logger: logging.Component("test")}, delegate)
+		texts := []string{}
+		for _, alert := range alerts()[before:] {
+			texts = append(texts, alert.Text)
+		}
+		return texts
+	}
+	missed := func(texts []string, level int64, reason string) bool {
+		for _, text := range texts {
+			if text == fmt.Sprintf("*Missed Endorsement* at level `%v` with baker `tz1inc...`: %v", level, reason) {
+				return true
+			}
+		}
+		return false
+	}
+
+	// Nothing seen before the mempool was watched
+	if texts := analyze(5001); !missed(texts, 5000, storage.MissUnknown) {
+		t.Error("Expected an unknown reason", texts)
+	}
+
+	// Someone else's endorsement shows the mempool was watched at 5001
+	for _, op := range pendingOperations(t, `{"applied": [{"hash": "opSomeone", "contents": [{"kind": "endorsement", "level": 5001, "slot": 9}]}]}`) {
+		a.inspect(op, a.bakers)
+	}
+	if texts := analyze(5002); !missed(texts, 5001, storage.MissNeverBroadcast) {
+		t.Error("Expected a missing broadcast", texts)
+	}
+
+	// Ours was broadcast, then included late
+	for _, op := range pendingOperations(t, `{"applied": [{"hash": "opOurs", "contents": [{"kind": "endorsement", "level": 5002, "slot": 4}]}]}`) {
+		a.inspect(op, a.bakers)
+	}
+	if texts := analyze(5003); !missed(texts, 5002, storage.MissNotIncluded) {
+		t.Error("Expected a broadcast endorsement that wasn't included", texts)
+	}
+	texts := analyze(5004)
+	if len(texts) != 1 || countAlerts(alerts(), "slack", "*Late Endorsement* at level `5002`") != 1 || countAlerts(alerts(), "slack", "*Missed Endorsement* at level `5002`") != 1 {
+		t.Error("Expected the late inclusion to alert without another miss", texts)
+	}
+	if i, _ := storage.GetInclusion(delegate, 5002); i.IncludedBy != "tz1inclusionbaker" || i.Latency() != 2 {
+		t.Errorf("Incorrect late inclusion %+v", i)
+	}
+}
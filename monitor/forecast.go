@@ -0,0 +1,135 @@
+package monitor
+
+import (
+	"fmt"
+
+	"github.com/nlopes/slack"
+	"gitlab.com/polychainlabs/tezos-network-monitor/alert"
+	"gitlab.com/polychainlabs/tezos-network-monitor/logging"
+	"gitlab.com/polychainlabs/tezos-network-monitor/metrics"
+	"gitlab.com/polychainlabs/tezos-network-monitor/storage"
+	"gitlab.com/polychainlabs/tezos-network-monitor/tzrpc"
+)
+
+// CheckForecast of these bakers' rights over the rest of the current cycle and
+// the next preserved cycles, and alert if their spendable balance won't cover
+// the deposits
+func (m *Monitor) CheckForecast(bakers []string) {
+	logger := logging.Component("forecast")
+	head := m.getCurrentBlock()
+	constants, err := tzrpc.GetConstants(head.Hash())
+	if err != nil {
+		logger.WithError(err).Error("Unable to get constants")
+		return
+	}
+
+	currentCycle := tzrpc.Cycle(head.Level())
+	for _, baker := range bakers {
+		delegate, err := tzrpc.GetDelegate(baker, head.Hash())
+		if err != nil {
+			logger.WithError(err).WithField("delegate", baker).Error("Unable to get delegate")
+			continue
+		}
+
+		cycles := []storage.CycleForecast{}
+		for cycle := currentCycle; cycle <= currentCycle+constants.PreservedCycles; cycle++ {
+			baking, err := tzrpc.FutureBakingRights(cycle, baker)
+			if err != nil {
+				logger.WithError(err).WithField("delegate", baker).WithField("cycle", cycle).Error("Unable to get baking rights")
+				break
+			}
+			endorsing, err := tzrpc.FutureEndorsingRights(cycle, baker)
+			if err != nil {
+				logger.WithError(err).WithField("delegate", baker).WithField("cycle", cycle).Error("Unable to get endorsing rights")
+				break
+			}
+			// Only the rights after the head are still to be deposited
+			cycles = append(cycles, storage.CycleForecast{
+				Cycle:            cycle,
+				Blocks:           baking.BlocksAfter(head.Level(), 0),
+				EndorsementSlots: endorsing.SlotCountAfter(head.Level()),
+			})
+		}
+
+		previous, _ := storage.GetForecast(baker)
+		f := storage.RecordForecast(forecastDeposits(storage.Forecast{
+			Delegate:  baker,
+			Level:     head.Level(),
+			Spendable: delegate.Balance - delegate.FrozenBalance,
+			Cycles:    cycles,
+		}, delegate, constants))
+		clearForecastMetrics(previous, f)
+		m.checkForecast(currentCycle, f)
+	}
+}
+
+// clearForecastMetrics of the cycles in the `previous` forecast that have
+// since passed
+func clearForecastMetrics(previous storage.Forecast, f storage.Forecast) {
+	for _, old := range previous.Cycles {
+		if len(f.Cycles) > 0 && old.Cycle >= f.Cycles[0].Cycle {
+			continue
+		}
+		metrics.ForecastDeposits.DeleteLabelValues(previous.Delegate, fmt.Sprint(old.Cycle))
+		metrics.ForecastAvailable.DeleteLabelValues(previous.Delegate, fmt.Sprint(old.Cycle))
+	}
+}
+
+// forecastDeposits required each cycle, and what's left available after them.
+// Before Tenderbake each right froze its own deposit, released at the end of
+// the cycle `preserved_cycles` after it was frozen, so the current cycle's
+// release is already spendable.  Tenderbake bakers keep a share of their
+// staking balance frozen instead, out of their full balance.  That isn't
+// derived from each cycle's rights, so every cycle gets the same bound from
+// the current staking balance
+func forecastDeposits(f storage.Forecast, delegate *tzrpc.Delegate, constants *tzrpc.Constants) storage.Forecast {
+	if constants.Tenderbake() {
+		for i := range f.Cycles {
+			c := &f.Cycles[i]
+			c.Deposits = constants.RequiredDeposit(delegate.StakingBalance)
+			c.Available = delegate.Balance - c.Deposits
+		}
+		return f
+	}
+
+	available := f.Spendable
+	for i := range f.Cycles {
+		c := &f.Cycles[i]
+		if i > 0 {
+			available += delegate.FrozenBalanceByCycle[c.Cycle-1-constants.PreservedCycles]
+		}
+		c.Deposits = c.Blocks*constants.BlockSecurityDeposit + c.EndorsementSlots*constants.EndorsementSecurityDeposit
+		available -= c.Deposits
+		c.Available = available
+	}
+	return f
+}
+
+// checkForecast for the first cycle we'd run short in, alerting once a cycle
+// and paging if it's the next one
+func (m *Monitor) checkForecast(currentCycle int64, f storage.Forecast) {
+	for _, c := range f.Cycles {
+		metrics.ForecastDeposits.WithLabelValues(f.Delegate, fmt.Sprint(c.Cycle)).Set(float64(c.Deposits) / 1e6)
+		metrics.ForecastAvailable.WithLabelValues(f.Delegate, fmt.Sprint(c.Cycle)).Set(float64(c.Available) / 1e6)
+	}
+	if f.WarnedCycle == currentCycle {
+		return
+	}
+
+	for _, c := range f.Cycles {
+		if c.Available >= 0 {
+			continue
+		}
+		storage.MarkForecastWarned(f.Delegate, currentCycle)
+		alert.PostSlack(&slack.WebhookMessage{
+			Text: fmt.Sprintf("*Deposit Shortfall* `%v` will be `%vꜩ` short of the `%vꜩ` deposits for its `%v` blocks and `%v` endorsement slots in cycle `%v`",
+				m.alias(f.Delegate), float64(-c.Available)/1e6, float64(c.Deposits)/1e6, c.Blocks, c.EndorsementSlots, c.Cycle),
+		})
+		if float64(c.Cycle-currentCycle) <= m.threshold("forecast", f.Delegate, "PageCyclesAhead", 1) {
+			title := fmt.Sprintf("Deposit shortfall for %v in cycle %v", m.alias(f.Delegate), c.Cycle)
+			body := fmt.Sprintf("%v needs %vꜩ more to cover its deposits in cycle %v, or it will lose rights.  Top up its balance now.", f.Delegate, float64(-c.Available)/1e6, c.Cycle)
+			alert.Page(title, body)
+		}
+		return
+	}
+}
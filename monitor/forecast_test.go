@@ -0,0 +1,88 @@
+package monitor
+
+import (
+	"fmt"
+	"testing"
+
+	"gitlab.com/polychainlabs/tezos-network-monitor/metrics"
+	"gitlab.com/polychainlabs/tezos-network-monitor/storage"
+	"gitlab.com/polychainlabs/tezos-network-monitor/tzrpc"
+)
+
+func TestForecastDeposits(t *testing.T) {
+	constants := &tzrpc.Constants{
+		PreservedCycles:            5,
+		BlockSecurityDeposit:       512e6,
+		EndorsementSecurityDeposit: 64e6,
+	}
+	f := forecastDeposits(storage.Forecast{
+		Spendable: 2000e6,
+		Cycles: []storage.CycleForecast{
+			{Cycle: 200, EndorsementSlots: 2},
+			{Cycle: 201, Blocks: 2, EndorsementSlots: 10},
+			{Cycle: 202, Blocks: 1, EndorsementSlots: 5},
+		},
+	}, &tzrpc.Delegate{FrozenBalanceByCycle: map[int64]int64{194: 300e6, 195: 100e6}}, constants)
+
+	// The rest of the current cycle, whose release is already spendable:
+	// 2000 - 2*64 = 1872
+	if f.Cycles[0].Deposits != 128e6 || f.Cycles[0].Available != 1872e6 {
+		t.Error("Incorrect current cycle", f.Cycles[0])
+	}
+	// 1872 + 100 released at the end of cycle 200 - 2*512 - 10*64 = 308
+	if f.Cycles[1].Deposits != 1664e6 || f.Cycles[1].Available != 308e6 {
+		t.Error("Incorrect first upcoming cycle", f.Cycles[1])
+	}
+	// 308 - 512 - 5*64 = -524
+	if f.Cycles[2].Deposits != 832e6 || f.Cycles[2].Available != -524e6 {
+		t.Error("Incorrect second upcoming cycle", f.Cycles[2])
+	}
+}
+
+func TestClearForecastMetrics(t *testing.T) {
+	cycles := func(cycles ...int64) []storage.CycleForecast {
+		forecasts := []storage.CycleForecast{}
+		for _, c := range cycles {
+			forecasts = append(forecasts, storage.CycleForecast{Cycle: c})
+		}
+		return forecasts
+	}
+	previous := storage.Forecast{Delegate: "tz1clear", Cycles: cycles(198, 199, 200)}
+	for _, c := range previous.Cycles {
+		metrics.ForecastDeposits.WithLabelValues("tz1clear", fmt.Sprint(c.Cycle)).Set(1)
+		metrics.ForecastAvailable.WithLabelValues("tz1clear", fmt.Sprint(c.Cycle)).Set(1)
+	}
+
+	// Passed cycles are removed, upcoming ones kept
+	clearForecastMetrics(previous, storage.Forecast{Delegate: "tz1clear", Cycles: cycles(200, 201)})
+	if metrics.ForecastDeposits.DeleteLabelValues("tz1clear", "198") || metrics.ForecastAvailable.DeleteLabelValues("tz1clear", "199") {
+		t.Error("Expected passed cycles to be removed")
+	}
+	if !metrics.ForecastDeposits.DeleteLabelValues("tz1clear", "200") {
+		t.Error("Expected the current cycle to be kept")
+	}
+}
+
+func TestForecastTenderbakeDeposits(t *testing.T) {
+	forecast := func(constants *tzrpc.Constants) storage.Forecast {
+		return forecastDeposits(storage.Forecast{
+			Spendable: 500e6,
+			Cycles:    []storage.CycleForecast{{Cycle: 201, Blocks: 2, EndorsementSlots: 10}, {Cycle: 202}},
+		}, &tzrpc.Delegate{Balance: 1500e6, FrozenBalance: 1000e6, StakingBalance: 20000e6}, constants)
+	}
+
+	// 10% of a 20000ꜩ staking balance out of a 1500ꜩ full balance
+	f := forecast(&tzrpc.Constants{PreservedCycles: 5, FrozenDepositsPercentage: 10})
+	for _, c := range f.Cycles {
+		if c.Deposits != 2000e6 || c.Available != -500e6 {
+			t.Error("Incorrect Ithaca cycle", c)
+		}
+	}
+
+	// Delegations of up to `limit_of_delegation_over_baking` times the deposit
+	// since Oxford
+	f = forecast(&tzrpc.Constants{PreservedCycles: 3, LimitOfDelegationOverBaking: 19})
+	if c := f.Cycles[0]; c.Deposits != 1000e6 || c.Available != 500e6 {
+		t.Error("Incorrect Oxford cycle", c)
+	}
+}
@@ -0,0 +1,120 @@
+package monitor
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nlopes/slack"
+	"gitlab.com/polychainlabs/tezos-network-monitor/alert"
+	"gitlab.com/polychainlabs/tezos-network-monitor/logging"
+	"gitlab.com/polychainlabs/tezos-network-monitor/storage"
+	"gitlab.com/polychainlabs/tezos-network-monitor/tzrpc"
+)
+
+// governanceAnalyzer records and announces votes cast by our bakers
+type governanceAnalyzer struct {
+	*Monitor
+	bakers []string
+}
+
+func (a governanceAnalyzer) Name() string {
+	return "governance"
+}
+
+func (a governanceAnalyzer) Analyze(b *BlockContext) {
+	m := a.Monitor
+	for _, vote := range b.Block.Votes() {
+		if !contains(a.bakers, vote.Source) {
+			continue
+		}
+		b.Logger.WithField("delegate", vote.Source).WithField("period", vote.Period).Info("Vote recorded")
+		storage.RecordVote(vote.Source, b.Level, vote.Period, vote.Kind, vote.Proposals, vote.Ballot)
+
+		text := fmt.Sprintf("*Vote Recorded* `%v` upvoted `%v` in voting period `%v`",
+			m.alias(vote.Source), strings.Join(vote.Proposals, "`, `"), vote.Period)
+		if vote.Kind == "ballot" {
+			text = fmt.Sprintf("*Vote Recorded* `%v` voted `%v` on `%v` in voting period `%v`",
+				m.alias(vote.Source), vote.Ballot, strings.Join(vote.Proposals, ""), vote.Period)
+		}
+		alert.PostSlack(&slack.WebhookMessage{Text: text})
+	}
+}
+
+// CheckGovernance announces new voting periods, and alerts once a period if
+// any of these bakers is allowed to vote but hasn't as the period ends
+func (m *Monitor) CheckGovernance(bakers []string) {
+	logger := logging.Component("governance")
+	head := m.getCurrentBlock()
+	period, err := tzrpc.GetCurrentPeriod(head.Hash())
+	if err != nil {
+		logger.WithError(err).Error("Unable to get voting period")
+		return
+	}
+	if previous := storage.RecordVotingPeriod(period.Index); previous >= 0 && previous != period.Index {
+		alert.PostSlack(&slack.WebhookMessage{
+			Text: fmt.Sprintf("*New Voting Period* `%v` period `%v` has begun", period.Kind, period.Index),
+		})
+	}
+
+	// Proposal periods only need a vote once there's something to vote for
+	expected := period.Voting()
+	if period.Kind == "proposal" {
+		proposals, err := tzrpc.GetProposals(head.Hash())
+		if err != nil {
+			logger.WithError(err).Error("Unable to get proposals")
+			return
+		}
+		expected = len(proposals) > 0
+	}
+	if !expected || period.Remaining < 0 {
+		return
+	}
+
+	listings, err := tzrpc.GetListings(head.Hash())
+	if err != nil {
+		logger.WithError(err).Error("Unable to get listings")
+		return
+	}
+	ballots := map[string]string{}
+	if period.Voting() {
+		if ballots, err = tzrpc.GetBallotList(head.Hash()); err != nil {
+			logger.WithError(err).Error("Unable to get ballots")
+			return
+		}
+	}
+	for _, baker := range bakers {
+		if _, listed := listings[baker]; !listed {
+			continue
+		}
+		m.checkVoted(baker, period, hasVoted(baker, period, ballots, head.Hash()))
+	}
+}
+
+// hasVoted reports whether `baker` has voted this period, on chain, so votes
+// cast before we started count.  Nodes before Jakarta can't say who upvoted a
+// proposal, so we fall back to the votes we've seen
+func hasVoted(baker string, period *tzrpc.VotingPeriod, ballots map[string]string, blockID string) bool {
+	if _, balloted := ballots[baker]; balloted {
+		return true
+	}
+	info, err := tzrpc.GetVotingInfo(baker, blockID)
+	if err == nil {
+		return info.Voted()
+	}
+	logging.Component("governance").WithError(err).WithField("delegate", baker).Debug("Unable to get voting info.  Using recorded votes")
+	return len(storage.GetPeriodVotes(baker, period.Index)) > 0
+}
+
+// checkVoted alerting once per period if `baker` hasn't voted as it ends
+func (m *Monitor) checkVoted(baker string, period *tzrpc.VotingPeriod, voted bool) {
+	if voted || float64(period.Remaining) > m.threshold("governance", baker, "SlackRemainingBlocks", 4096) {
+		return
+	}
+	if !storage.MarkGovernanceWarned(baker, period.Index) {
+		return
+	}
+	alert.PostSlack(&slack.WebhookMessage{
+		Text: fmt.Sprintf("*Vote Missing* `%v` hasn't voted in `%v` period `%v`, which ends in `%v` blocks",
+			m.alias(baker), period.Kind, period.Index, period.Remaining),
+	})
+}
@@ -0,0 +1,58 @@
+package monitor
+
+import (
+	"testing"
+
+	"gitlab.com/polychainlabs/tezos-network-monitor/storage"
+	"gitlab.com/polychainlabs/tezos-network-monitor/tzrpc"
+)
+
+func TestCheckVoted(t *testing.T) {
+	alerts := captureAlerts()
+	m := Monitor{}
+	period := &tzrpc.VotingPeriod{Index: 42, Kind: "exploration", Remaining: 4097}
+
+	// One block more than the threshold, or already voted
+	m.checkVoted("tz1governance", period, false)
+	period.Remaining = 100
+	m.checkVoted("tz1governance", period, true)
+	if len(alerts()) != 0 {
+		t.Error("Expected no warning", alerts())
+	}
+
+	// Exactly the threshold warns, once a period
+	period.Remaining = 4096
+	m.checkVoted("tz1governance", period, false)
+	period.Remaining = 100
+	m.checkVoted("tz1governance", period, false)
+	if countAlerts(alerts(), "slack", "`exploration` period `42`, which ends in `4096` blocks") != 1 || len(alerts()) != 1 {
+		t.Error("Expected a single warning with 4096 blocks left", alerts())
+	}
+
+	// And again in the next period
+	m.checkVoted("tz1governance", &tzrpc.VotingPeriod{Index: 43, Kind: "cooldown", Remaining: 100}, false)
+	if countAlerts(alerts(), "slack", "`cooldown` period `43`") != 1 {
+		t.Error("Expected a warning in the next period", alerts())
+	}
+}
+
+func TestCheckGovernanceProposals(t *testing.T) {
+	alerts := captureAlerts()
+	defer serveChain(3000, "tz1someone", map[string]string{
+		"chains/main/blocks/BL3000/votes/current_period": `{"voting_period": {"index": 90, "kind": "proposal", "start_position": 0},
+			"position": 20000, "remaining": 480}`,
+		"chains/main/blocks/BL3000/votes/proposals":                          `[["PtProposal", 5000]]`,
+		"chains/main/blocks/BL3000/votes/listings":                           `[{"pkh": "tz1upvoted", "voting_power": "100"}, {"pkh": "tz1silent", "voting_power": "100"}, {"pkh": "tz1seen", "voting_power": "100"}]`,
+		"chains/main/blocks/BL3000/context/delegates/tz1upvoted/voting_info": `{"voting_power": "100", "current_proposals": ["PtProposal"], "remaining_proposals": 19}`,
+		"chains/main/blocks/BL3000/context/delegates/tz1silent/voting_info":  `{"voting_power": "100", "remaining_proposals": 20}`,
+	})()
+
+	// Upvotes made before we started count, and nodes without voting info
+	// fall back to the votes we've recorded
+	storage.RecordVote("tz1seen", 2990, 90, "proposals", []string{"PtProposal"}, "")
+	m := Monitor{}
+	m.CheckGovernance([]string{"tz1upvoted", "tz1silent", "tz1seen"})
+	if countAlerts(alerts(), "slack", "*Vote Missing*") != 1 || countAlerts(alerts(), "slack", "`tz1sil...`") != 1 {
+		t.Error("Expected a warning for the baker that hasn't voted only", alerts())
+	}
+}
@@ -0,0 +1,208 @@
+package monitor
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nlopes/slack"
+	"github.com/sirupsen/logrus"
+	"gitlab.com/polychainlabs/tezos-network-monitor/alert"
+	"gitlab.com/polychainlabs/tezos-network-monitor/logging"
+	"gitlab.com/polychainlabs/tezos-network-monitor/metrics"
+	"gitlab.com/polychainlabs/tezos-network-monitor/storage"
+	"gitlab.com/polychainlabs/tezos-network-monitor/tzrpc"
+)
+
+// How long to wait before reopening the mempool stream
+const mempoolRetry = 5 * time.Second
+
+// CheckMempool every pending operation, including those the node refused or
+// delayed, which the stream doesn't carry
+func (m *Monitor) CheckMempool(bakers []string) {
+	operations, err := tzrpc.GetPendingOperations()
+	if err != nil {
+		logging.Component("mempool").WithError(err).Error("Unable to get pending operations")
+		return
+	}
+	counts := map[string]int{}
+	for _, op := range operations {
+		counts[op.Status]++
+		m.inspect(op, bakers)
+	}
+	for _, status := range tzrpc.MempoolStatuses {
+		metrics.MempoolOperations.WithLabelValues(status).Set(float64(counts[status]))
+	}
+}
+
+// WatchMempool stream of applied operations, so threats are caught between
+// checks.  Reconnects whenever the stream ends, until the monitor is done
+func (m *Monitor) WatchMempool(bakers []string) {
+	if !m.enabled("mempool") {
+		return
+	}
+	logger := logging.Component("mempool")
+	for m.ctx.Err() == nil {
+		err := tzrpc.MonitorOperations(m.ctx, func(op tzrpc.PendingOperation) {
+			m.inspect(op, bakers)
+		})
+		if err != nil {
+			logger.WithError(err).Warn("Mempool stream ended")
+		}
+		select {
+		case <-m.ctx.Done():
+		case <-time.After(mempoolRetry):
+		}
+	}
+}
+
+// inspect a pending operation, skipping it instead of crashing if it can't be
+// parsed.  Mempool operations aren't validated yet, so may be malformed
+func (m *Monitor) inspect(op tzrpc.PendingOperation, bakers []string) {
+	defer func() {
+		if r := recover(); r != nil {
+			logging.Component("mempool").WithField("operation", op.Hash).WithField("panic", r).Error("Unable to inspect pending operation")
+		}
+	}()
+	m.inspectPending(op, bakers)
+}
+
+// inspectPending operation for outgoing transactions, double signing evidence
+// against our bakers, and our own endorsements being refused.  What the
+// mempool saw of our endorsements is kept to explain misses
+func (m *Monitor) inspectPending(op tzrpc.PendingOperation, bakers []string) {
+	logger := logging.Component("mempool").WithFields(logrus.Fields{
+		"operation": op.Hash,
+		"status":    op.Status,
+	})
+
+	if storage.MarkPendingSeen(op.Hash, "") {
+		for _, tx := range op.Transactions() {
+			if contains(m.addresses, tx.Source) && !m.isDestinationWhitelisted(tx.Source, tx.Destination) {
+				m.pendingTransfer(logger, op, tx)
+			}
+		}
+		for _, e := range op.Evidence() {
+			if accused := m.accused(e); contains(bakers, accused) {
+				m.pendingEvidence(logger, op, e, accused)
+			}
+		}
+	}
+
+	if !storage.MarkPendingSeen(op.Hash, op.Status) {
+		return
+	}
+	for _, c := range op.ConsensusOperations() {
+		storage.MarkMempoolWatched(c.Level)
+		delegate := m.ourConsensusDelegate(c.Level, c.Slot, bakers)
+		if len(delegate) == 0 {
+			continue
+		}
+		if !strings.HasPrefix(c.Kind, "pre") {
+			storage.RecordMempoolEndorsement(delegate, c.Level, op.Status, op.Errors)
+		}
+		switch op.Status {
+		case tzrpc.MempoolRefused, tzrpc.MempoolBranchRefused, tzrpc.MempoolBranchDelayed:
+			m.consensusRejected(logger, op, c, delegate)
+		}
+	}
+}
+
+// pendingTransfer from our address to a destination that isn't whitelisted
+func (m *Monitor) pendingTransfer(logger *logrus.Entry, op tzrpc.PendingOperation, tx tzrpc.Transaction) {
+	logger.WithFields(logrus.Fields{
+		"source":      tx.Source,
+		"destination": tx.Destination,
+		"amount":      tx.Amount,
+	}).Error("Pending transfer to a non-whitelisted destination")
+	metrics.MempoolThreats.WithLabelValues("transfer").Inc()
+
+	alert.PostSlack(&slack.WebhookMessage{
+		Text: fmt.Sprintf("*Pending Transfer* of `%v`ꜩ from `%v` to non-whitelisted `%v` is `%v` in the mempool: `%v`",
+			tx.Amount/1e6, m.alias(tx.Source), m.alias(tx.Destination), op.Status, op.Hash),
+	})
+	title := fmt.Sprintf("Pending transfer of %vꜩ from %v", tx.Amount/1e6, m.alias(tx.Source))
+	body := fmt.Sprintf("To %v with fee %vꜩ, not yet included.  Operation %v", m.alias(tx.Destination), tx.Fee/1e6, op.Hash)
+	alert.Page(title, body)
+}
+
+// pendingEvidence of double signing by one of our bakers
+func (m *Monitor) pendingEvidence(logger *logrus.Entry, op tzrpc.PendingOperation, e tzrpc.Evidence, baker string) {
+	logger.WithFields(logrus.Fields{
+		"delegate": baker,
+		"kind":     e.Kind,
+		"level":    e.Level,
+	}).Error("Pending double signing evidence against our baker")
+	metrics.MempoolThreats.WithLabelValues("evidence").Inc()
+
+	name := evidenceName(e.Kind)
+	alert.PostSlack(&slack.WebhookMessage{
+		Text: fmt.Sprintf("*PENDING %v EVIDENCE* against `%v` at level `%v` is in the mempool: `%v`. SHUT THIS BAKER DOWN NOW",
+			strings.ToUpper(name), m.alias(baker), e.Level, op.Hash),
+	})
+	title := fmt.Sprintf("Pending %v evidence against %v", name, m.alias(baker))
+	body := fmt.Sprintf("Evidence for level %v is waiting to be included, operation %v.  SHUT THIS BAKER DOWN NOW, BEFORE IT SIGNS AGAIN.", e.Level, op.Hash)
+	alert.Page(title, body)
+}
+
+// consensusRejected operation of one of our bakers
+func (m *Monitor) consensusRejected(logger *logrus.Entry, op tzrpc.PendingOperation, c tzrpc.ConsensusOperation, baker string) {
+	logger.WithFields(logrus.Fields{
+		"delegate": baker,
+		"kind":     c.Kind,
+		"level":    c.Level,
+		"errors":   strings.Join(op.Errors, ","),
+	}).Warn("Consensus operation rejected by the mempool")
+	metrics.MempoolThreats.WithLabelValues("consensus_" + op.Status).Inc()
+
+	alert.PostSlack(&slack.WebhookMessage{
+		Text: fmt.Sprintf("*%v %v* by `%v` at level `%v`: `%v`",
+			strings.Title(strings.Replace(op.Status, "_", " ", -1)), strings.Title(c.Kind), m.alias(baker), c.Level, strings.Join(op.Errors, ", ")),
+	})
+}
+
+// accused delegate of double signing evidence, from the rights at its level,
+// or "" if it can't be told
+func (m *Monitor) accused(e tzrpc.Evidence) string {
+	if e.Kind == "double_baking_evidence" {
+		rights, err := tzrpc.GetBakingRights(e.Level)
+		if err != nil {
+			return ""
+		}
+		return rights.Delegate(e.Priority)
+	}
+	return m.consensusDelegate(e.Level, e.Slot)
+}
+
+// consensusDelegate owning `slot` at `level`, or "" if it can't be told
+func (m *Monitor) consensusDelegate(level int64, slot int64) string {
+	if level <= 0 || slot < 0 {
+		return ""
+	}
+	rights, err := tzrpc.GetEndorsingRights(level)
+	if err != nil {
+		return ""
+	}
+	return rights.SlotDelegate(slot)
+}
+
+// ourConsensusDelegate of `bakers` owning `slot` at `level`, or "" if it's
+// someone else's.  Our bakers' prefetched rights answer this, rather than
+// looking up the rights of every level the network endorses
+func (m *Monitor) ourConsensusDelegate(level int64, slot int64, bakers []string) string {
+	if level <= 0 || slot < 0 {
+		return ""
+	}
+	for _, baker := range bakers {
+		slots, err := tzrpc.EndorsingSlots(level, baker)
+		if err != nil {
+			continue
+		}
+		for _, s := range slots {
+			if s == slot {
+				return baker
+			}
+		}
+	}
+	return ""
+}
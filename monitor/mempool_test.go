@@ -0,0 +1,88 @@
+package monitor
+
+import (
+	"testing"
+
+	"gitlab.com/polychainlabs/tezos-network-monitor/storage"
+	"gitlab.com/polychainlabs/tezos-network-monitor/tzrpc"
+)
+
+// pendingOperations parsed from a mempool `body`
+func pendingOperations(t *testing.T, body string) []tzrpc.PendingOperation {
+	defer serveNode(map[string]string{"chains/main/mempool/pending_operations": body})()
+	operations, err := tzrpc.GetPendingOperations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return operations
+}
+
+func TestInspectPendingTransfer(t *testing.T) {
+	alerts := captureAlerts()
+	m := Monitor{
+		addresses: []string{"tz1mempoolours"},
+		whitelist: map[string][]string{"tz1mempoolours": {"tz1mempoolfriend"}},
+	}
+	operations := pendingOperations(t, `{"applied": [
+		{"hash": "opThief", "contents": [{"kind": "transaction", "source": "tz1mempoolours", "destination": "tz1mempoolthief", "amount": "5000000", "fee": "1420"}]},
+		{"hash": "opFriend", "contents": [{"kind": "transaction", "source": "tz1mempoolours", "destination": "tz1mempoolfriend", "amount": "1000000", "fee": "1420"}]},
+		{"hash": "opMalformed", "contents": [{"kind": "transaction", "source": 7}]}
+	]}`)
+
+	// Each transfer pages once, whichever way it's seen
+	for _, op := range operations {
+		m.inspect(op, nil)
+		m.inspect(op, nil)
+	}
+	if countAlerts(alerts(), "pagerduty", "Pending transfer of 5ꜩ") != 1 || countAlerts(alerts(), "pagerduty", "") != 1 {
+		t.Error("Expected a single page for the transfer to a non-whitelisted destination", alerts())
+	}
+}
+
+func TestInspectPendingEvidence(t *testing.T) {
+	alerts := captureAlerts()
+	rights := `[{"level": 280, "delegate": "tz1mempoolbaker", "round": 2}]`
+	defer serveNode(map[string]string{
+		"chains/main/blocks/280/helpers/baking_rights?level=280":  rights,
+		"chains/main/blocks/head/helpers/baking_rights?level=280": rights,
+	})()
+	m := Monitor{}
+
+	// The accused is whoever had the rights to the double baked round
+	e := tzrpc.Evidence{Kind: "double_baking_evidence", Level: 280, Priority: 2, Slot: -1}
+	if accused := m.accused(e); accused != "tz1mempoolbaker" {
+		t.Error("Expected our baker to be accused, found", accused)
+	}
+	if accused := m.accused(tzrpc.Evidence{Kind: "double_attestation_evidence", Level: 280, Slot: -1}); accused != "" {
+		t.Error("Expected no one accused without a slot, found", accused)
+	}
+
+	operations := pendingOperations(t, `{"branch_delayed": [{"hash": "opEvidence", "contents": [
+		{"kind": "double_baking_evidence", "bh1": {"level": 280, "fitness": ["02", "00000118", "", "ffffffff", "00000002"]}}
+	], "error": []}]}`)
+	m.inspect(operations[0], []string{"tz1mempoolbaker"})
+	if countAlerts(alerts(), "pagerduty", "Pending double baking evidence") != 1 {
+		t.Error("Expected a page for evidence against our baker", alerts())
+	}
+}
+
+func TestInspectPendingRefused(t *testing.T) {
+	alerts := captureAlerts()
+	rights := `[{"level": 290, "delegates": [{"delegate": "tz1mempoolendorser", "first_slot": 3, "attestation_power": 2}]}]`
+	defer serveNode(map[string]string{
+		"chains/main/blocks/290/helpers/endorsing_rights?level=290":  rights,
+		"chains/main/blocks/head/helpers/endorsing_rights?level=290": rights,
+	})()
+	m := Monitor{}
+
+	operations := pendingOperations(t, `{"refused": [["opRefused", {"contents": [
+		{"kind": "attestation", "slot": 3, "level": 290, "round": 0}
+	], "error": [{"kind": "temporary", "id": "proto.validate.consensus_operation_for_old_level"}]}]]}`)
+	m.inspect(operations[0], []string{"tz1mempoolendorser"})
+	if countAlerts(alerts(), "slack", "*Refused Attestation*") != 1 {
+		t.Error("Expected an alert for our refused attestation", alerts())
+	}
+	if i, _ := storage.GetInclusion("tz1mempoolendorser", 290); len(i.MempoolStatuses) != 1 || len(i.MempoolErrors) != 1 {
+		t.Errorf("Expected what the mempool saw to be kept %+v", i)
+	}
+}
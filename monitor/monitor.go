@@ -2,9 +2,12 @@ package monitor
 
 import (
 	"context"
-	"log"
+	"strconv"
 
+	"github.com/sirupsen/logrus"
 	"gitlab.com/polychainlabs/tezos-network-monitor/alert"
+	"gitlab.com/polychainlabs/tezos-network-monitor/logging"
+	"gitlab.com/polychainlabs/tezos-network-monitor/metrics"
 	"gitlab.com/polychainlabs/tezos-network-monitor/tzrpc"
 )
 
@@ -14,6 +17,9 @@ type Monitor struct {
 	addresses []string
 	aliases   map[string]string
 	whitelist map[string][]string
+	analyzers []Analyzer
+	checks    []Check
+	config    map[string]CheckConfig
 }
 
 // New monitor
@@ -36,15 +42,25 @@ func (m *Monitor) getCurrentBlock() *tzrpc.Block {
 	// Get latest Block
 	block, err := tzrpc.GetBlock(bootstrapped.Block, 0)
 	m.check(err)
+	metrics.HeadLevel.Set(float64(block.Level()))
 	return block
 }
 
-func (m *Monitor) alias(address string) string {
-	return alert.Alias(m.aliases, address)
+// cycle of a level, as a metric label
+func cycle(level int64) string {
+	return strconv.FormatInt(tzrpc.Cycle(level), 10)
+}
+
+// withLevel tags log lines with the level and its cycle
+func withLevel(logger *logrus.Entry, level int64) *logrus.Entry {
+	return logger.WithFields(logrus.Fields{
+		"level": level,
+		"cycle": tzrpc.Cycle(level),
+	})
 }
 
-func (m *Monitor) logError(err error) {
-	log.Println(err)
+func (m *Monitor) alias(address string) string {
+	return alert.Alias(m.aliases, address)
 }
 
 func (m *Monitor) check(err error) {
@@ -53,6 +69,6 @@ func (m *Monitor) check(err error) {
 
 func (m *Monitor) fatalError(err error) {
 	if err != nil {
-		log.Fatal(err)
+		logging.Component("monitor").Fatal(err)
 	}
 }
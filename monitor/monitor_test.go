@@ -0,0 +1,28 @@
+package monitor
+
+import (
+	"strings"
+
+	"gitlab.com/polychainlabs/tezos-network-monitor/alert"
+)
+
+// captureAlerts instead of sending them, returning a function that lists the
+// alerts captured since
+func captureAlerts() func() []alert.Record {
+	alert.SetDryRun(true)
+	before := len(alert.Captured())
+	return func() []alert.Record {
+		return alert.Captured()[before:]
+	}
+}
+
+// countAlerts sent by `notifier` that contain `text`
+func countAlerts(alerts []alert.Record, notifier string, text string) int {
+	count := 0
+	for _, a := range alerts {
+		if a.Notifier == notifier && strings.Contains(a.Text, text) {
+			count++
+		}
+	}
+	return count
+}
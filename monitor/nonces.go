@@ -0,0 +1,90 @@
+package monitor
+
+import (
+	"fmt"
+
+	"github.com/nlopes/slack"
+	"github.com/sirupsen/logrus"
+	"gitlab.com/polychainlabs/tezos-network-monitor/alert"
+	"gitlab.com/polychainlabs/tezos-network-monitor/metrics"
+	"gitlab.com/polychainlabs/tezos-network-monitor/storage"
+	"gitlab.com/polychainlabs/tezos-network-monitor/tzrpc"
+)
+
+// nonceAnalyzer tracks seed nonces committed in blocks our bakers baked, and
+// alerts until each is revealed.  A nonce committed in cycle c must be revealed
+// early in cycle c+1, or during all of it before Kathmandu, or the block's
+// rewards are forfeited
+type nonceAnalyzer struct {
+	*Monitor
+	bakers []string
+}
+
+func (a nonceAnalyzer) Name() string {
+	return "nonces"
+}
+
+func (a nonceAnalyzer) Analyze(b *BlockContext) {
+	m := a.Monitor
+
+	// Commitments by our bakers
+	baker := b.Block.Baker()
+	if hash := b.Block.SeedNonceHash(); len(hash) > 0 && contains(a.bakers, baker) {
+		b.Logger.WithField("delegate", baker).Info("Committed seed nonce")
+		storage.RecordNonceCommitment(baker, b.Level, hash)
+	}
+
+	// Revelations by anyone
+	for _, level := range b.Block.SeedNonceRevelations() {
+		storage.RecordNonceRevelation(level, b.Level)
+	}
+
+	for _, nonce := range storage.GetUnrevealedNonces() {
+		m.checkNonce(b, nonce)
+	}
+}
+
+// checkNonce still waiting on a revelation, alerting as its window closes and
+// paging once it has
+func (m *Monitor) checkNonce(b *BlockContext, nonce storage.Nonce) {
+	constants, err := tzrpc.GetConstants(fmt.Sprint(nonce.Level))
+	if err != nil {
+		b.Logger.WithError(err).Error("Unable to get constants")
+		return
+	}
+	windowStart, _ := tzrpc.CycleLevels(nonce.Cycle + 1)
+	windowEnd := constants.NonceRevelationEnd(nonce.Cycle)
+	remaining := windowEnd - b.Level
+	logger := b.Logger.WithFields(logrus.Fields{
+		"delegate":     nonce.Delegate,
+		"nonce_level":  nonce.Level,
+		"window_end":   windowEnd,
+		"levels_to_go": remaining,
+	})
+
+	if remaining < 0 {
+		logger.Error("Seed nonce never revealed")
+		storage.MarkNonceForfeited(nonce.Level)
+		metrics.SeedNoncesForfeited.WithLabelValues(nonce.Delegate, cycle(nonce.Level)).Inc()
+
+		title := fmt.Sprintf("Seed nonce never revealed by %v", m.alias(nonce.Delegate))
+		body := fmt.Sprintf("The nonce committed at level %v wasn't revealed by level %v, so its rewards are forfeited.  Is the baker's nonce file intact?", nonce.Level, windowEnd)
+		alert.Page(title, body)
+		return
+	}
+
+	// Nonces can't be revealed before their window opens, and since Kathmandu
+	// it's only a fraction of a cycle
+	warnLevels := 1024.0
+	if constants.NonceRevelationThreshold > 0 {
+		warnLevels = float64(constants.NonceRevelationThreshold) / 2
+	}
+	if nonce.Warned || b.Level < windowStart || float64(remaining) > m.threshold("nonces", nonce.Delegate, "SlackRemainingLevels", warnLevels) {
+		return
+	}
+	logger.Warn("Seed nonce not yet revealed")
+	storage.MarkNonceWarned(nonce.Level)
+	alert.PostSlack(&slack.WebhookMessage{
+		Text: fmt.Sprintf("*Unrevealed Seed Nonce* committed at level `%v` by `%v`. `%v` levels left to reveal it", nonce.Level, m.alias(nonce.Delegate), remaining),
+	})
+}
@@ -0,0 +1,91 @@
+package monitor
+
+import (
+	"fmt"
+	"testing"
+
+	"gitlab.com/polychainlabs/tezos-network-monitor/logging"
+	"gitlab.com/polychainlabs/tezos-network-monitor/storage"
+	"gitlab.com/polychainlabs/tezos-network-monitor/tzrpc"
+)
+
+// unrevealedNonce committed at `level`, or nil once revealed or forfeited
+func unrevealedNonce(level int64) *storage.Nonce {
+	for _, n := range storage.GetUnrevealedNonces() {
+		if n.Level == level {
+			return &n
+		}
+	}
+	return nil
+}
+
+func nonceBlock(level int64) *BlockContext {
+	return &BlockContext{Level: level, Logger: logging.Component("test")}
+}
+
+func TestCheckNonce(t *testing.T) {
+	alerts := captureAlerts()
+	m := Monitor{}
+	commitLevel := int64(819232)
+	defer serveNode(map[string]string{
+		fmt.Sprintf("chains/main/blocks/%v/context/constants", commitLevel): `{"nonce_revelation_threshold": 256}`,
+	})()
+	storage.RecordNonceCommitment("tz1a", commitLevel, "nce")
+
+	// The window is the first 256 levels of the following cycle, not all of it
+	windowStart, cycleEnd := tzrpc.CycleLevels(tzrpc.Cycle(commitLevel) + 1)
+	windowEnd := windowStart + 255
+
+	// Half the window left
+	m.checkNonce(nonceBlock(windowStart+126), *unrevealedNonce(commitLevel))
+	if unrevealedNonce(commitLevel).Warned || len(alerts()) != 0 {
+		t.Error("Expected no warning with 129 levels left", alerts())
+	}
+	m.checkNonce(nonceBlock(windowStart+127), *unrevealedNonce(commitLevel))
+	if !unrevealedNonce(commitLevel).Warned || countAlerts(alerts(), "slack", "`128` levels left to reveal it") != 1 {
+		t.Error("Expected a warning with 128 levels left", alerts())
+	}
+
+	// Only warned once, and still revealable at the last level of the window
+	m.checkNonce(nonceBlock(windowEnd), *unrevealedNonce(commitLevel))
+	if unrevealedNonce(commitLevel) == nil || len(alerts()) != 1 {
+		t.Error("Expected a single warning and the nonce revealable at the end of its window", alerts())
+	}
+
+	// Closed long before the end of the cycle
+	m.checkNonce(nonceBlock(windowEnd+1), *unrevealedNonce(commitLevel))
+	if windowEnd+1 >= cycleEnd || unrevealedNonce(commitLevel) != nil || countAlerts(alerts(), "pagerduty", "Seed nonce never revealed") != 1 {
+		t.Error("Expected the nonce to be forfeited once its window closed", alerts())
+	}
+}
+
+func TestCheckNonceBeforeKathmandu(t *testing.T) {
+	alerts := captureAlerts()
+	m := Monitor{config: map[string]CheckConfig{"nonces": {Thresholds: map[string]float64{"SlackRemainingLevels": 100000}}}}
+	commitLevel := int64(819264)
+	defer serveNode(map[string]string{
+		fmt.Sprintf("chains/main/blocks/%v/context/constants", commitLevel): `{"blocks_per_commitment": 32}`,
+	})()
+	storage.RecordNonceCommitment("tz1b", commitLevel, "nce")
+	windowStart, windowEnd := tzrpc.CycleLevels(tzrpc.Cycle(commitLevel) + 1)
+
+	// Nonces can't be revealed until the following cycle
+	m.checkNonce(nonceBlock(windowStart-1), *unrevealedNonce(commitLevel))
+	if unrevealedNonce(commitLevel).Warned || len(alerts()) != 0 {
+		t.Error("Expected no warning before the window opened", alerts())
+	}
+	m.checkNonce(nonceBlock(windowStart), *unrevealedNonce(commitLevel))
+	if countAlerts(alerts(), "slack", "*Unrevealed Seed Nonce* committed at level `819264`") != 1 {
+		t.Error("Expected a warning once the window opened", alerts())
+	}
+
+	// The whole following cycle
+	m.checkNonce(nonceBlock(windowEnd), *unrevealedNonce(commitLevel))
+	if unrevealedNonce(commitLevel) == nil {
+		t.Error("Expected the nonce revealable at the end of the following cycle")
+	}
+	m.checkNonce(nonceBlock(windowEnd+1), *unrevealedNonce(commitLevel))
+	if unrevealedNonce(commitLevel) != nil || countAlerts(alerts(), "pagerduty", "Seed nonce never revealed") != 1 {
+		t.Error("Expected the nonce to be forfeited after the following cycle", alerts())
+	}
+}
@@ -0,0 +1,29 @@
+package monitor
+
+import "sync"
+
+// RunConcurrently every check, with at most `workers` running at once, and
+// wait for all of them to finish
+func (m *Monitor) RunConcurrently(workers int, checks []func()) {
+	if workers < 1 {
+		workers = 1
+	}
+
+	queue := make(chan func())
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for check := range queue {
+				check()
+			}
+		}()
+	}
+
+	for _, check := range checks {
+		queue <- check
+	}
+	close(queue)
+	wg.Wait()
+}
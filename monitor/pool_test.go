@@ -0,0 +1,66 @@
+package monitor
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRunConcurrently(t *testing.T) {
+	var lock sync.Mutex
+	running, maxRunning, finished := 0, 0, 0
+
+	checks := []func(){}
+	for i := 0; i < 10; i++ {
+		checks = append(checks, func() {
+			lock.Lock()
+			running++
+			if running > maxRunning {
+				maxRunning = running
+			}
+			lock.Unlock()
+
+			time.Sleep(time.Millisecond)
+
+			lock.Lock()
+			running--
+			finished++
+			lock.Unlock()
+		})
+	}
+
+	m := Monitor{}
+	m.RunConcurrently(3, checks)
+
+	if finished != 10 {
+		t.Errorf("Expected 10 checks to finish but %v did", finished)
+	}
+	if maxRunning > 3 {
+		t.Errorf("Expected at most 3 concurrent checks but saw %v", maxRunning)
+	}
+}
+
+func TestAnalyzeDelegates(t *testing.T) {
+	var lock sync.Mutex
+	analyzed := map[string]bool{}
+	analyze := func(b *BlockContext, delegate string) {
+		if delegate == "tz1broken" {
+			panic("unexpected layout")
+		}
+		lock.Lock()
+		defer lock.Unlock()
+		analyzed[delegate] = true
+	}
+
+	// The others are still analyzed, and the failure reaches runAnalyzer
+	defer func() {
+		if r := recover(); r != "unexpected layout" {
+			t.Error("Expected the panic to be raised again but found", r)
+		}
+		if len(analyzed) != 3 {
+			t.Error("Expected 3 delegates analyzed but found", analyzed)
+		}
+	}()
+	m := Monitor{}
+	m.analyzeDelegates(&BlockContext{Level: 1}, []string{"tz1a", "tz1broken", "tz1b", "tz1c"}, 2, analyze)
+}
@@ -0,0 +1,113 @@
+package monitor
+
+import (
+	"fmt"
+
+	"github.com/nlopes/slack"
+	"github.com/sirupsen/logrus"
+	"gitlab.com/polychainlabs/tezos-network-monitor/alert"
+	"gitlab.com/polychainlabs/tezos-network-monitor/logging"
+	"gitlab.com/polychainlabs/tezos-network-monitor/metrics"
+	"gitlab.com/polychainlabs/tezos-network-monitor/storage"
+	"gitlab.com/polychainlabs/tezos-network-monitor/tzrpc"
+)
+
+// protocolAnalyzer alerts on the last block before a protocol upgrade
+type protocolAnalyzer struct {
+	*Monitor
+}
+
+func (a protocolAnalyzer) Name() string {
+	return "protocol"
+}
+
+func (a protocolAnalyzer) Analyze(b *BlockContext) {
+	protocol, next := b.Block.Protocol(), b.Block.NextProtocol()
+	if protocol == next || !storage.MarkProtocolAnnounced("activation "+next) {
+		return
+	}
+	b.Logger.WithField("next_protocol", next).Warn("Protocol upgrade at next block")
+
+	if b.Block.SupportsProtocol(next) {
+		alert.PostSlack(&slack.WebhookMessage{
+			Text: fmt.Sprintf("*Protocol Upgrade* `%v` activates at level `%v`, replacing `%v`", next, b.Level+1, protocol),
+		})
+		return
+	}
+	title := fmt.Sprintf("Unsupported protocol %v activates at level %v", next, b.Level+1)
+	body := "The monitor can't parse blocks of this protocol, so it will stop analyzing blocks.  Upgrade the monitor."
+	alert.Page(title, body)
+}
+
+// CheckProtocol announces a proposal once it's being promoted or adopted,
+// paging ahead of activation if it's unsupported
+func (m *Monitor) CheckProtocol() {
+	logger := logging.Component("protocol")
+	head := m.getCurrentBlock()
+	period, err := tzrpc.GetCurrentPeriod(head.Hash())
+	if err != nil {
+		logger.WithError(err).Error("Unable to get voting period")
+		return
+	}
+	switch period.Kind {
+	case "promotion_vote", "promotion", "adoption":
+	default:
+		return
+	}
+	proposal, err := tzrpc.GetCurrentProposal(head.Hash())
+	if err != nil || len(proposal) == 0 {
+		return
+	}
+	m.announceProposal(proposal, period, head.SupportsProtocol(proposal))
+}
+
+// announceProposal once per voting period kind
+func (m *Monitor) announceProposal(proposal string, period *tzrpc.VotingPeriod, supported bool) {
+	if !storage.MarkProtocolAnnounced(period.Kind + " " + proposal) {
+		return
+	}
+	logging.Component("protocol").WithFields(logrus.Fields{
+		"proposal":  proposal,
+		"period":    period.Kind,
+		"supported": supported,
+	}).Info("Upcoming protocol")
+
+	alert.PostSlack(&slack.WebhookMessage{
+		Text: fmt.Sprintf("*Upcoming Protocol* `%v` is in the `%v` period, `%v` blocks remaining. Supported by the monitor: `%v`",
+			proposal, period.Kind, period.Remaining, supported),
+	})
+	if !supported && period.Kind == "adoption" {
+		title := fmt.Sprintf("Unsupported protocol %v will activate in %v blocks", proposal, period.Remaining)
+		body := "The monitor can't parse blocks of this protocol yet.  Upgrade the monitor before activation."
+		alert.Page(title, body)
+	}
+}
+
+// supportedBlock records the block's protocol, and pages once per protocol
+// if it can't be parsed.  Unknown Tenderbake protocols are parsed, with a
+// warning
+func (m *Monitor) supportedBlock(level int64, block *tzrpc.Block, logger *logrus.Entry) bool {
+	protocol := block.Protocol()
+	supported := block.Supported()
+	storage.RecordBlockProtocol(level, protocol, supported)
+	if supported {
+		if !tzrpc.IsSupportedProtocol(protocol) && storage.MarkProtocolAnnounced("assumed "+protocol) {
+			logger.WithField("protocol", protocol).Warn("Unknown protocol.  Parsing it like earlier Tenderbake protocols")
+			alert.PostSlack(&slack.WebhookMessage{
+				Text: fmt.Sprintf("*Unknown Protocol* `%v` from level `%v` is parsed like earlier Tenderbake protocols.  Check the monitor still reads it correctly", protocol, level),
+			})
+		}
+		return true
+	}
+
+	// The level is recorded as skipped, so it can be backfilled once supported
+	metrics.UnsupportedBlocks.WithLabelValues(protocol).Inc()
+	logger.WithField("protocol", protocol).Error("Unsupported protocol.  Skipping analyzers")
+	if storage.MarkProtocolAnnounced("unsupported " + protocol) {
+		title := fmt.Sprintf("Unsupported protocol %v at level %v", protocol, level)
+		body := fmt.Sprintf("The monitor can't parse blocks of this protocol, so is skipping baking, endorsing and every other block analyzer.  "+
+			"Upgrade the monitor, or add the protocol under Protocols in config.yaml, then backfill from level %v.  /api/protocols lists the levels skipped.", level)
+		alert.Page(title, body)
+	}
+	return false
+}
@@ -0,0 +1,86 @@
+package monitor
+
+import (
+	"fmt"
+	"testing"
+
+	"gitlab.com/polychainlabs/tezos-network-monitor/alert"
+	"gitlab.com/polychainlabs/tezos-network-monitor/logging"
+	"gitlab.com/polychainlabs/tezos-network-monitor/storage"
+	"gitlab.com/polychainlabs/tezos-network-monitor/tzrpc"
+)
+
+func TestAnnounceProposal(t *testing.T) {
+	alert.SetDryRun(true)
+	m := Monitor{}
+	period := &tzrpc.VotingPeriod{Index: 60, Kind: "adoption", Remaining: 8192}
+
+	m.announceProposal("PtUnknownProtocol", period, false)
+	if storage.MarkProtocolAnnounced("adoption PtUnknownProtocol") {
+		t.Error("Expected the proposal to be announced")
+	}
+	if !storage.MarkProtocolAnnounced("promotion PtUnknownProtocol") {
+		t.Error("Expected each period to be announced separately")
+	}
+}
+
+func TestRecordBlockProtocol(t *testing.T) {
+	storage.RecordBlockProtocol(100, "PtOld", true)
+	storage.RecordBlockProtocol(101, "PtOld", true)
+	storage.RecordBlockProtocol(102, "PtNew", false)
+	storage.RecordBlockProtocol(103, "PtNew", false)
+
+	if p := storage.GetBlockProtocol(101); p != "PtOld" {
+		t.Errorf("Expected PtOld at 101, got %v", p)
+	}
+	if p := storage.GetBlockProtocol(103); p != "PtNew" {
+		t.Errorf("Expected PtNew at 103, got %v", p)
+	}
+	if p := storage.GetBlockProtocol(99); p != "" {
+		t.Errorf("Expected no protocol at 99, got %v", p)
+	}
+	protocols := storage.GetProtocols()
+	last := protocols[len(protocols)-1]
+	if len(protocols) < 2 || last.FirstLevel != 102 || last.LastLevel != 103 || last.Supported {
+		t.Errorf("Unexpected protocol ranges %+v", protocols)
+	}
+}
+
+func TestAnalyzeUnsupportedProtocol(t *testing.T) {
+	alerts := captureAlerts()
+	block := func(level int64) string {
+		return fmt.Sprintf(`{"hash": "BL%v", "header": {"level": %v}, "metadata": {"protocol": "PtFuture"}, "operations": [[], [], [], []]}`, level, level)
+	}
+	defer serveNode(map[string]string{
+		"chains/main/blocks/BL7000~0": block(7000),
+		"chains/main/blocks/BL7001~0": block(7001),
+	})()
+	recorder := &levelRecorder{}
+	m := Monitor{}
+	m.RegisterAnalyzers(recorder)
+
+	// Skipped, and recorded so the level can be backfilled
+	unsupported, err := tzrpc.GetBlock("BL7000", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.analyze(7000, unsupported, logging.Component("test"), false, levelWindow{})
+	if len(recorder.levels) != 0 || countAlerts(alerts(), "pagerduty", "Unsupported protocol PtFuture at level 7000") != 1 {
+		t.Error("Expected the block to be skipped with a page", recorder.levels, alerts())
+	}
+	skipped := storage.GetUnsupportedProtocols()
+	if len(skipped) == 0 || skipped[len(skipped)-1].FirstLevel != 7000 {
+		t.Error("Expected the skipped level to be recorded", skipped)
+	}
+
+	// Protocols added in config.yaml are analyzed
+	tzrpc.SupportProtocol("PtFuture", "099_PtFuture")
+	supported, err := tzrpc.GetBlock("BL7001", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.analyze(7001, supported, logging.Component("test"), false, levelWindow{})
+	if fmt.Sprint(recorder.levels) != "[7001]" {
+		t.Error("Expected the newly supported block to be analyzed", recorder.levels)
+	}
+}
@@ -0,0 +1,164 @@
+package monitor
+
+import (
+	"fmt"
+
+	"github.com/nlopes/slack"
+	"github.com/sirupsen/logrus"
+	"gitlab.com/polychainlabs/tezos-network-monitor/alert"
+	"gitlab.com/polychainlabs/tezos-network-monitor/metrics"
+	"gitlab.com/polychainlabs/tezos-network-monitor/storage"
+	"gitlab.com/polychainlabs/tezos-network-monitor/tzrpc"
+)
+
+// rewardsAnalyzer attributes balance updates to our bakers, and compares
+// each completed cycle's rewards with what its rights could have earned
+type rewardsAnalyzer struct {
+	*Monitor
+	bakers []string
+}
+
+func (a rewardsAnalyzer) Name() string {
+	return "rewards"
+}
+
+func (a rewardsAnalyzer) Analyze(b *BlockContext) {
+	m := a.Monitor
+	for _, u := range b.Block.BalanceUpdates() {
+		delegate := u.Owner()
+		if !contains(a.bakers, delegate) {
+			continue
+		}
+		category, amount := rewardCategory(u)
+		if len(category) == 0 {
+			continue
+		}
+		// Tenderbake updates aren't for a cycle, so they count towards the
+		// cycle they happen in
+		cycle := u.Cycle
+		if cycle < 0 {
+			cycle = tzrpc.Cycle(b.Level)
+		}
+		storage.RecordReward(delegate, cycle, category, amount)
+		metrics.Rewards.WithLabelValues(delegate, fmt.Sprint(cycle), category).Add(float64(amount) / 1e6)
+	}
+
+	// Compare the previous cycle once it's complete
+	first, _ := tzrpc.CycleLevels(tzrpc.Cycle(b.Level))
+	if b.Level != first || b.Level <= 1 {
+		return
+	}
+	completed := tzrpc.Cycle(b.Level - 1)
+	constants, err := tzrpc.GetRewardConstants(fmt.Sprint(b.Level-1), completed)
+	if err != nil {
+		b.Logger.WithError(err).Error("Unable to get constants")
+		return
+	}
+	for _, delegate := range a.bakers {
+		m.checkLostRewards(b, delegate, storage.CompareRewards(delegate, completed, constants))
+	}
+}
+
+// checkLostRewards and alert if more than a threshold percent of the expected
+// rewards weren't earned
+func (m *Monitor) checkLostRewards(b *BlockContext, delegate string, c storage.RewardComparison) {
+	metrics.RewardsLost.WithLabelValues(delegate, fmt.Sprint(c.Cycle)).Set(float64(c.Lost) / 1e6)
+	expected := c.ExpectedBaking + c.ExpectedEndorsing
+	b.Logger.WithFields(logrus.Fields{
+		"delegate": delegate,
+		"expected": expected,
+		"lost":     c.Lost,
+	}).Info("Compared cycle rewards")
+	if expected == 0 {
+		return
+	}
+
+	lostPercent := 100 * float64(c.Lost) / float64(expected)
+	if lostPercent > m.threshold("rewards", delegate, "SlackLostPercent", 5) {
+		alert.PostSlack(&slack.WebhookMessage{
+			Text: fmt.Sprintf("*Lost Rewards* in cycle `%v` by `%v`. Earned `%vꜩ` of `%vꜩ` expected (`%.1f%%` lost)",
+				c.Cycle, m.alias(delegate), float64(c.EarnedBaking+c.EarnedEndorsing)/1e6, float64(expected)/1e6, lostPercent),
+		})
+	}
+}
+
+// rewardCategory of a balance update, and its amount as a positive number of
+// mutez.  Tenderbake credits are attributed by the minted or accumulated
+// balance that funded them, otherwise only frozen balance updates are
+// attributed, since these carry the delegate and cycle
+func rewardCategory(u tzrpc.BalanceUpdate) (string, int64) {
+	if u.Change == 0 {
+		return "", 0
+	}
+	if len(u.Source) > 0 {
+		return tenderbakeRewardCategory(u)
+	}
+	if u.Kind != "freezer" {
+		return "", 0
+	}
+
+	switch u.Origin {
+	case "block":
+		// Frozen balances are released into the block closing a cycle
+		if u.Change < 0 {
+			switch u.Category {
+			case "deposits":
+				return storage.UnfrozenDeposits, -u.Change
+			case "rewards":
+				return storage.UnfrozenRewards, -u.Change
+			case "fees":
+				return storage.UnfrozenFees, -u.Change
+			}
+			return "", 0
+		}
+		switch u.Category {
+		case "deposits":
+			return storage.BakingDeposits, u.Change
+		case "rewards":
+			return storage.BakingRewards, u.Change
+		}
+	case "endorsement", "endorsement_with_slot", "attestation", "attestation_with_dal":
+		switch u.Category {
+		case "deposits":
+			return storage.EndorsementDeposits, u.Change
+		case "rewards":
+			return storage.EndorsementRewards, u.Change
+		}
+	case "seed_nonce_revelation":
+		if u.Category == "rewards" {
+			return storage.RevelationRewards, u.Change
+		}
+	case "double_baking_evidence", "double_endorsement_evidence", "double_preendorsement_evidence",
+		"double_attestation_evidence", "double_preattestation_evidence":
+		if u.Change < 0 {
+			return storage.Slashed, -u.Change
+		}
+		return storage.AccusationRewards, u.Change
+	}
+
+	if u.Category == "fees" && u.Change > 0 {
+		return storage.Fees, u.Change
+	}
+	return "", 0
+}
+
+// tenderbakeRewardCategory of a credit funded by minting rewards or
+// accumulating fees, whether paid to the baker's contract or to its deposits
+func tenderbakeRewardCategory(u tzrpc.BalanceUpdate) (string, int64) {
+	if u.Change < 0 {
+		return "", 0
+	}
+	switch u.Source {
+	case "baking rewards", "baking bonuses":
+		return storage.BakingRewards, u.Change
+	case "endorsing rewards", "attesting rewards":
+		return storage.EndorsementRewards, u.Change
+	case "nonce revelation rewards":
+		return storage.RevelationRewards, u.Change
+	case "double signing evidence rewards":
+		return storage.AccusationRewards, u.Change
+	case "block fees":
+		return storage.Fees, u.Change
+	}
+	return "", 0
+}
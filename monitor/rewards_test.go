@@ -0,0 +1,37 @@
+package monitor
+
+import (
+	"testing"
+
+	"gitlab.com/polychainlabs/tezos-network-monitor/storage"
+	"gitlab.com/polychainlabs/tezos-network-monitor/tzrpc"
+)
+
+func TestRewardCategory(t *testing.T) {
+	tests := []struct {
+		update   tzrpc.BalanceUpdate
+		category string
+		amount   int64
+	}{
+		{tzrpc.BalanceUpdate{Kind: "contract", Change: -512000000, Origin: "block"}, "", 0},
+		{tzrpc.BalanceUpdate{Kind: "freezer", Category: "rewards", Change: 16000000, Origin: "block"}, storage.BakingRewards, 16000000},
+		{tzrpc.BalanceUpdate{Kind: "freezer", Category: "deposits", Change: -512000000, Origin: "block"}, storage.UnfrozenDeposits, 512000000},
+		{tzrpc.BalanceUpdate{Kind: "freezer", Category: "rewards", Change: 2000000, Origin: "endorsement"}, storage.EndorsementRewards, 2000000},
+		{tzrpc.BalanceUpdate{Kind: "freezer", Category: "fees", Change: 1420, Origin: "transaction"}, storage.Fees, 1420},
+		{tzrpc.BalanceUpdate{Kind: "freezer", Category: "deposits", Change: -64000000, Origin: "double_endorsement_evidence"}, storage.Slashed, 64000000},
+		{tzrpc.BalanceUpdate{Kind: "freezer", Category: "rewards", Change: 32000000, Origin: "double_endorsement_evidence"}, storage.AccusationRewards, 32000000},
+		{tzrpc.BalanceUpdate{Kind: "minted", Category: "baking rewards", Change: -5000000, Origin: "block", Cycle: -1}, "", 0},
+		{tzrpc.BalanceUpdate{Kind: "contract", Change: 5000000, Origin: "block", Cycle: -1, Source: "baking rewards"}, storage.BakingRewards, 5000000},
+		{tzrpc.BalanceUpdate{Kind: "freezer", Category: "deposits", Change: 4000000, Origin: "block", Cycle: -1, Source: "baking bonuses"}, storage.BakingRewards, 4000000},
+		{tzrpc.BalanceUpdate{Kind: "contract", Change: 9000000, Origin: "block", Cycle: -1, Source: "attesting rewards"}, storage.EndorsementRewards, 9000000},
+		{tzrpc.BalanceUpdate{Kind: "contract", Change: 2450, Origin: "block", Cycle: -1, Source: "block fees"}, storage.Fees, 2450},
+		{tzrpc.BalanceUpdate{Kind: "contract", Change: 1000, Origin: "block", Cycle: -1, Source: "subsidy"}, "", 0},
+		{tzrpc.BalanceUpdate{Kind: "freezer", Category: "deposits", Change: 6000000000, Origin: "block", Cycle: -1}, storage.BakingDeposits, 6000000000},
+	}
+	for _, test := range tests {
+		category, amount := rewardCategory(test.update)
+		if category != test.category || amount != test.amount {
+			t.Errorf("Expected %v of %v for %+v but found %v of %v", test.amount, test.category, test.update, amount, category)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package monitor
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nlopes/slack"
+	"gitlab.com/polychainlabs/tezos-network-monitor/alert"
+	"gitlab.com/polychainlabs/tezos-network-monitor/storage"
+)
+
+// slashingAnalyzer alerts on double baking and endorsing by anyone, and pages
+// if it was us
+type slashingAnalyzer struct {
+	*Monitor
+}
+
+func (a slashingAnalyzer) Name() string {
+	return "slashing"
+}
+
+func (a slashingAnalyzer) Analyze(b *BlockContext) {
+	m := a.Monitor
+	level := b.Level
+
+	// Alert on double baking
+	for _, double := range b.Block.DoubleBakings() {
+		// Slack if anyone has double baked
+		alert.PostSlack(&slack.WebhookMessage{
+			Text: fmt.Sprintf("*Double Baking* found at level `%v`. `%vꜩ` slashed", double.Level, double.SlashedAmount/1e6),
+		})
+		for _, address := range m.addresses {
+			if address == double.SlashedBaker {
+				// Page if you've double baked :(
+				alert.PostSlack(&slack.WebhookMessage{
+					Text: fmt.Sprintf("*WE DOUBLE BAKED* At level `%v` by baker `%v`. `%vꜩ` slashed. SHUT THIS BAKER DOWN NOW.", double.Level, double.SlashedBaker, double.SlashedAmount/1e6),
+				})
+
+				title := fmt.Sprintf("WE DOUBLE BAKED with %v", address)
+				body := fmt.Sprintf("%vꜩ was just slashed at level %v.  SHUT THIS BAKER DOWN NOW, AND STAY OFFLINE FOR THE REMAINED OF THE CYCLE.", double.SlashedAmount/1e6, level)
+				alert.Page(title, body)
+			}
+		}
+		storage.RecordSlashing("double_baking", double.SlashedBaker, level, b.Block.Cycle(),
+			int64(double.SlashedAmount), m.isOurs(double.SlashedBaker))
+	}
+
+	// Alert on double endorsements, and Tenderbake's double (pre)attestations
+	for _, double := range b.Block.DoubleEndorsements() {
+		name := evidenceName(double.Kind)
+		// Slack if anyone has double endorsed
+		alert.PostSlack(&slack.WebhookMessage{
+			Text: fmt.Sprintf("*%v* found at cycle `%v`. `%vꜩ` slashed", strings.Title(name), double.Cycle, double.SlashedAmount/1e6),
+		})
+		for _, address := range m.addresses {
+			if address == double.SlashedEndorser {
+				// Page if you've double endorsed :(
+				alert.PostSlack(&slack.WebhookMessage{
+					Text: fmt.Sprintf("*WE COMMITTED A %v* At cycle `%v` by endorser `%v`. `%vꜩ` slashed. SHUT THIS ENDORSER DOWN NOW", strings.ToUpper(name), double.Cycle, double.SlashedEndorser, double.SlashedAmount/1e6),
+				})
+
+				title := fmt.Sprintf("WE COMMITTED A %v with %v at level %v", strings.ToUpper(name), address, level)
+				body := fmt.Sprintf("%vꜩ was just slashed.  SHUT THIS ENDORSER DOWN NOW, AND STAY OFFLINE FOR THE REMAINED OF THE CYCLE", double.SlashedAmount/1e6)
+				alert.Page(title, body)
+			}
+		}
+		storage.RecordSlashing(strings.TrimSuffix(double.Kind, "_evidence"), double.SlashedEndorser, level, b.Block.Cycle(),
+			int64(double.SlashedAmount), m.isOurs(double.SlashedEndorser))
+	}
+}
+
+// evidenceName of a double signing evidence kind, e.g. "double attestation"
+func evidenceName(kind string) string {
+	return strings.Replace(strings.TrimSuffix(kind, "_evidence"), "_", " ", -1)
+}
@@ -0,0 +1,48 @@
+package monitor
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"gitlab.com/polychainlabs/tezos-network-monitor/logging"
+	"gitlab.com/polychainlabs/tezos-network-monitor/storage"
+	"gitlab.com/polychainlabs/tezos-network-monitor/tzrpc"
+)
+
+func TestAnalyzeSlashing(t *testing.T) {
+	alerts := captureAlerts()
+	routes := map[string]string{}
+	for hash, path := range map[string]string{"BLdoubleBaking": "../tests/double_baking.json", "BLdoubleEndorsement": "../tests/double_endorsement.json"} {
+		body, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		routes["chains/main/blocks/"+hash+"~0"] = string(body)
+	}
+	defer serveNode(routes)()
+
+	a := slashingAnalyzer{&Monitor{}}
+	for _, hash := range []string{"BLdoubleBaking", "BLdoubleEndorsement"} {
+		block, err := tzrpc.GetBlock(hash, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		a.Analyze(&BlockContext{Level: block.Level(), Block: block, Logger: logging.Component("test")})
+	}
+
+	// Both kinds are slashed amounts, in tez in alerts and mutez in storage
+	if countAlerts(alerts(), "slack", "*Double Baking* found at level `305673`. `64ꜩ` slashed") != 1 {
+		t.Error("Expected the double baking to alert with the tez slashed", alerts())
+	}
+	slashings := storage.GetSlashings(2)
+	if len(slashings) != 2 || slashings[0].Kind != "double_baking" || slashings[0].Amount != 64000000 ||
+		slashings[1].Kind != "double_endorsement" || slashings[1].Amount <= 0 {
+		t.Errorf("Expected positive slashed amounts but found %+v", slashings)
+	}
+
+	// Callers get their own copy
+	slashings[0].Amount = 0
+	if storage.GetSlashings(2)[0].Amount != 64000000 {
+		t.Error("Expected stored slashings to be unaffected by callers")
+	}
+}
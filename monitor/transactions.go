@@ -0,0 +1,57 @@
+package monitor
+
+import (
+	"fmt"
+
+	"github.com/nlopes/slack"
+	"gitlab.com/polychainlabs/tezos-network-monitor/alert"
+)
+
+// transactionAnalyzer alerts when transactions are sent or received
+type transactionAnalyzer struct {
+	*Monitor
+}
+
+func (a transactionAnalyzer) Name() string {
+	return "transactions"
+}
+
+func (a transactionAnalyzer) Analyze(b *BlockContext) {
+	m := a.Monitor
+	for _, tx := range b.Block.Transactions() {
+		for _, address := range m.addresses {
+			if address == tx.Source {
+				// Slack when transactions sent _from_ your address
+				alert.PostSlack(&slack.WebhookMessage{
+					Text: fmt.Sprintf("*Sent* `%v`ꜩ from `%v` to `%v` with `%v`ꜩ fee",
+						tx.Amount/1e6, m.alias(tx.Source), m.alias(tx.Destination), tx.Fee/1e6),
+				})
+				// Page if destination address is not whitelisted
+				if !m.isDestinationWhitelisted(tx.Source, tx.Destination) {
+					title := fmt.Sprintf("Sent %vꜩ from %v", tx.Amount/1e6, m.alias(tx.Source))
+					body := fmt.Sprintf("To %v with fee %vꜩ at level %v",
+						m.alias(tx.Destination), tx.Fee/1e6, b.Level)
+					alert.Page(title, body)
+				}
+			}
+			if address == tx.Destination {
+				// Slack when transactions sent _to_ your address
+				alert.PostSlack(&slack.WebhookMessage{
+					Text: fmt.Sprintf("*Received* `%v`ꜩ at `%v`", tx.Amount/1e6, m.alias(tx.Destination)),
+				})
+			}
+		}
+	}
+}
+
+func (m *Monitor) isDestinationWhitelisted(source string, destination string) bool {
+	if _, ok := m.whitelist[source]; !ok {
+		return false
+	}
+	for _, address := range m.whitelist[source] {
+		if address == destination {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"gitlab.com/polychainlabs/tezos-network-monitor/alert"
+	"gitlab.com/polychainlabs/tezos-network-monitor/monitor"
+	"gitlab.com/polychainlabs/tezos-network-monitor/report"
+	"gitlab.com/polychainlabs/tezos-network-monitor/tzrpc"
+)
+
+// printReport for a cycle, scanning it first since storage only holds what
+// this process has analyzed
+func printReport(m *monitor.Monitor, c *config, args []string) {
+	flags := flag.NewFlagSet("report", flag.ExitOnError)
+	cycle := flags.Int64("cycle", -1, "Cycle to report on.  Defaults to the last completed cycle")
+	format := flags.String("format", report.Markdown, "Output format: markdown, csv or json")
+	flags.Parse(args)
+
+	if *cycle < 0 {
+		bootstrapped, err := tzrpc.GetBootstrapped()
+		if err != nil {
+			logger.Fatalln("Unable to find the current cycle: ", err)
+		}
+		head, err := tzrpc.GetBlock(bootstrapped.Block, 0)
+		if err != nil {
+			logger.Fatalln("Unable to find the current cycle: ", err)
+		}
+		*cycle = tzrpc.Cycle(head.Level()) - 1
+	}
+
+	// Scan the cycle, plus the next level which holds its last endorsements
+	alert.SetDryRun(true)
+	first, last := tzrpc.CycleLevels(*cycle)
+	logger.Infof("Scanning cycle %v", *cycle)
+	m.Backfill(first, last+1, c.Workers)
+
+	out, err := report.Build(*cycle, c.Bakers, c.Aliases).Format(*format)
+	if err != nil {
+		logger.Fatalln("Unable to format report: ", err)
+	}
+	fmt.Println(out)
+}
@@ -0,0 +1,41 @@
+package report
+
+import (
+	"github.com/nlopes/slack"
+	"gitlab.com/polychainlabs/tezos-network-monitor/alert"
+	"gitlab.com/polychainlabs/tezos-network-monitor/monitor"
+	"gitlab.com/polychainlabs/tezos-network-monitor/tzrpc"
+)
+
+// Analyzer posting each cycle's report to slack once the cycle ends
+type Analyzer struct {
+	bakers  []string
+	aliases map[string]string
+}
+
+// NewAnalyzer reporting on these bakers
+func NewAnalyzer(bakers []string, aliases map[string]string) *Analyzer {
+	return &Analyzer{bakers: bakers, aliases: aliases}
+}
+
+// Name of the analyzer
+func (a *Analyzer) Name() string {
+	return "report"
+}
+
+// Analyze the block, reporting on the previous cycle if this is the first
+// block of a new one.  Endorsements of a cycle's last level are only included
+// in this block, so it must be analyzed after the endorsing analyzer
+func (a *Analyzer) Analyze(b *monitor.BlockContext) {
+	cycle := tzrpc.Cycle(b.Level)
+	first, _ := tzrpc.CycleLevels(cycle)
+	if b.Level != first || cycle == 0 {
+		return
+	}
+
+	r := Build(cycle-1, a.bakers, a.aliases)
+	b.Logger.WithField("cycle", cycle-1).Info("Posting cycle report")
+	alert.PostSlack(&slack.WebhookMessage{
+		Text: "```" + r.Markdown() + "```",
+	})
+}
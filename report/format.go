@@ -0,0 +1,128 @@
+package report
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Formats a report can be written in
+const (
+	Markdown = "markdown"
+	CSV      = "csv"
+	JSON     = "json"
+)
+
+// Format the report as Markdown, CSV or JSON
+func (r *Report) Format(format string) (string, error) {
+	switch format {
+	case Markdown:
+		return r.Markdown(), nil
+	case CSV:
+		return r.CSV()
+	case JSON:
+		out, err := json.MarshalIndent(r, "", "  ")
+		return string(out), err
+	}
+	return "", fmt.Errorf("Unknown report format %v", format)
+}
+
+// Markdown with a section per baker
+func (r *Report) Markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Cycle %v report\n\nLevels %v through %v\n", r.Cycle, r.FirstLevel, r.LastLevel)
+	for _, br := range r.Bakers {
+		fmt.Fprintf(&b, "\n## %v\n\n`%v` - %v levels analyzed, staking balance %vꜩ, %v delegators\n\n",
+			br.Alias, br.Baker, br.LevelsAnalyzed, br.StakingBalance, br.Delegators)
+
+		b.WriteString("| Priority | Rights | Baked | Stolen | Missed |\n|---|---|---|---|---|\n")
+		for _, p := range br.Baking {
+			fmt.Fprintf(&b, "| %v | %v | %v | %v | %v |\n", p.Priority, p.Rights, p.Baked, p.Stolen, p.Missed)
+		}
+
+		if len(br.TakenBy) > 0 {
+			fmt.Fprintf(&b, "\nMissed blocks baked by: %v\n", bakerCounts(br.TakenBy))
+		}
+		if len(br.StolenFrom) > 0 {
+			fmt.Fprintf(&b, "\nBlocks stolen from: %v\n", bakerCounts(br.StolenFrom))
+		}
+
+		fmt.Fprintf(&b, "\nEndorsements: %v of %v slots made, %v missed\n",
+			br.Endorsing.Made, br.Endorsing.Slots, br.Endorsing.Missed)
+
+		fmt.Fprintf(&b, "\nDelegations: %v joined, %v left, %+vꜩ net\n",
+			br.Delegations.Added, br.Delegations.Removed, br.Delegations.Net)
+
+		fmt.Fprintf(&b, "\nRewards: %vꜩ of %vꜩ expected, %vꜩ lost, %vꜩ fees\n",
+			tez(br.Rewards.Earned), tez(br.Rewards.Expected), tez(br.Rewards.Lost), tez(br.Rewards.Fees))
+
+		if len(br.Slashings) == 0 {
+			b.WriteString("\nNo slashing events\n")
+		}
+		for _, s := range br.Slashings {
+			fmt.Fprintf(&b, "\n**Slashed** for %v at level %v: %vꜩ\n", s.Kind, s.Level, tez(s.Amount))
+		}
+	}
+	return b.String()
+}
+
+// CSV with a row per baker and priority
+func (r *Report) CSV() (string, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Write([]string{
+		"cycle", "baker", "alias", "levels_analyzed", "priority", "rights", "baked", "stolen", "missed",
+		"endorsement_slots", "endorsements_made", "endorsements_missed", "slashings", "staking_balance", "delegators",
+		"rewards_expected", "rewards_earned", "rewards_lost", "fees", "delegations_added", "delegations_removed", "delegations_net",
+	})
+	for _, br := range r.Bakers {
+		baking := br.Baking
+		if len(baking) == 0 {
+			baking = []PriorityRecord{{Priority: -1}}
+		}
+		for _, p := range baking {
+			w.Write([]string{
+				i64(r.Cycle), br.Baker, br.Alias, i64(br.LevelsAnalyzed),
+				i64(p.Priority), i64(p.Rights), i64(p.Baked), i64(p.Stolen), i64(p.Missed),
+				i64(br.Endorsing.Slots), i64(br.Endorsing.Made), i64(br.Endorsing.Missed),
+				strconv.Itoa(len(br.Slashings)), br.StakingBalance, strconv.Itoa(br.Delegators),
+				i64(br.Rewards.Expected), i64(br.Rewards.Earned), i64(br.Rewards.Lost), i64(br.Rewards.Fees),
+				strconv.Itoa(br.Delegations.Added), strconv.Itoa(br.Delegations.Removed), i64(br.Delegations.Net),
+			})
+		}
+	}
+	w.Flush()
+	return buf.String(), w.Error()
+}
+
+func i64(i int64) string {
+	return strconv.FormatInt(i, 10)
+}
+
+// tez from mutez
+func tez(mutez int64) float64 {
+	return float64(mutez) / 1e6
+}
+
+// bakerCounts most first, e.g. "tz1a (3), tz1b (1)"
+func bakerCounts(counts map[string]int64) string {
+	bakers := []string{}
+	for baker := range counts {
+		bakers = append(bakers, baker)
+	}
+	sort.Slice(bakers, func(i, j int) bool {
+		if counts[bakers[i]] != counts[bakers[j]] {
+			return counts[bakers[i]] > counts[bakers[j]]
+		}
+		return bakers[i] < bakers[j]
+	})
+	parts := []string{}
+	for _, baker := range bakers {
+		parts = append(parts, fmt.Sprintf("%v (%v)", baker, counts[baker]))
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,63 @@
+package report
+
+import (
+	"strings"
+	"testing"
+)
+
+func testReport() *Report {
+	return &Report{
+		Cycle:      200,
+		FirstLevel: 819201,
+		LastLevel:  823296,
+		Bakers: []BakerReport{{
+			Baker:          "tz1a",
+			Alias:          "My Baker",
+			LevelsAnalyzed: 4096,
+			Baking: []PriorityRecord{
+				{Priority: 0, Rights: 10, Baked: 9, Missed: 1},
+				{Priority: 1, Rights: 3, Baked: 1, Stolen: 1},
+			},
+			Endorsing:      EndorsingRecord{Slots: 100, Made: 98, Missed: 2},
+			Rewards:        Rewards{Expected: 525000000, Earned: 500000000, Lost: 25000000, Fees: 5020},
+			StakingBalance: "100000",
+			Delegators:     12,
+			Delegations:    DelegationRecord{Added: 2, Removed: 1, Net: 4000},
+			TakenBy:        map[string]int64{"tz1b": 1},
+			StolenFrom:     map[string]int64{"tz1c": 1, "tz1d": 2},
+		}},
+	}
+}
+
+func TestCSV(t *testing.T) {
+	out, err := testReport().CSV()
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected a header and a row per priority but found %v lines", len(lines))
+	}
+	if lines[1] != "200,tz1a,My Baker,4096,0,10,9,0,1,100,98,2,0,100000,12,525000000,500000000,25000000,5020,2,1,4000" {
+		t.Error("Unexpected row: ", lines[1])
+	}
+}
+
+func TestMarkdown(t *testing.T) {
+	out := testReport().Markdown()
+	if !strings.Contains(out, "Missed blocks baked by: tz1b (1)") || !strings.Contains(out, "Blocks stolen from: tz1d (2), tz1c (1)") {
+		t.Error("Expected block attribution in", out)
+	}
+}
+
+func TestFormat(t *testing.T) {
+	for _, format := range []string{Markdown, CSV, JSON} {
+		out, err := testReport().Format(format)
+		if err != nil || !strings.Contains(out, "tz1a") {
+			t.Errorf("Expected %v report to mention the baker", format)
+		}
+	}
+	if _, err := testReport().Format("pdf"); err == nil {
+		t.Error("Expected unknown formats to fail")
+	}
+}
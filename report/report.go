@@ -0,0 +1,173 @@
+package report
+
+import (
+	"fmt"
+	"sort"
+
+	"gitlab.com/polychainlabs/tezos-network-monitor/alert"
+	"gitlab.com/polychainlabs/tezos-network-monitor/storage"
+	"gitlab.com/polychainlabs/tezos-network-monitor/tzrpc"
+)
+
+// Report of each baker's performance over one cycle
+type Report struct {
+	Cycle      int64         `json:"cycle"`
+	FirstLevel int64         `json:"first_level"`
+	LastLevel  int64         `json:"last_level"`
+	Bakers     []BakerReport `json:"bakers"`
+}
+
+// BakerReport for one cycle
+type BakerReport struct {
+	Baker          string           `json:"baker"`
+	Alias          string           `json:"alias"`
+	LevelsAnalyzed int64            `json:"levels_analyzed"`
+	Baking         []PriorityRecord `json:"baking"`
+	Endorsing      EndorsingRecord  `json:"endorsing"`
+	Slashings      []Slashing       `json:"slashings"`
+	Rewards        Rewards          `json:"rewards"`
+	StakingBalance string           `json:"staking_balance"`
+	Delegators     int              `json:"delegators"`
+	Delegations    DelegationRecord `json:"delegations"`
+	// TakenBy bakers that baked the blocks we missed, and StolenFrom those
+	// we baked ahead of, with how many blocks each
+	TakenBy    map[string]int64 `json:"taken_by"`
+	StolenFrom map[string]int64 `json:"stolen_from"`
+}
+
+// DelegationRecord of contracts that joined or left during the cycle, with
+// the net change in tez
+type DelegationRecord struct {
+	Added   int   `json:"added"`
+	Removed int   `json:"removed"`
+	Net     int64 `json:"net"`
+}
+
+// PriorityRecord of blocks we had rights to bake at one priority
+type PriorityRecord struct {
+	Priority int64 `json:"priority"`
+	Rights   int64 `json:"rights"`
+	Baked    int64 `json:"baked"`
+	Missed   int64 `json:"missed"`
+	Stolen   int64 `json:"stolen"`
+}
+
+// EndorsingRecord of endorsement slots
+type EndorsingRecord struct {
+	Slots  int64 `json:"slots"`
+	Made   int64 `json:"made"`
+	Missed int64 `json:"missed"`
+}
+
+// Rewards earned during the cycle in mutez, against the most our rights could
+// have earned
+type Rewards struct {
+	Expected int64 `json:"expected"`
+	Earned   int64 `json:"earned"`
+	Lost     int64 `json:"lost"`
+	Fees     int64 `json:"fees"`
+	Deposits int64 `json:"deposits"`
+}
+
+// Slashing of this baker during the cycle
+type Slashing struct {
+	Kind   string `json:"kind"`
+	Level  int64  `json:"level"`
+	Amount int64  `json:"amount"`
+}
+
+// Build a report for `cycle` from what's been recorded in storage, with
+// staking balances and delegators as of the cycle's last level
+func Build(cycle int64, bakers []string, aliases map[string]string) *Report {
+	first, last := tzrpc.CycleLevels(cycle)
+	r := Report{Cycle: cycle, FirstLevel: first, LastLevel: last}
+	for _, baker := range bakers {
+		r.Bakers = append(r.Bakers, buildBaker(cycle, last, baker, aliases))
+	}
+	return &r
+}
+
+func buildBaker(cycle int64, lastLevel int64, baker string, aliases map[string]string) BakerReport {
+	br := BakerReport{
+		Baker:     baker,
+		Alias:     alert.Alias(aliases, baker),
+		Slashings: []Slashing{},
+	}
+
+	// Baking by priority
+	byPriority := map[int64]*PriorityRecord{}
+	bakings := storage.GetCycleBakings(baker, cycle)
+	br.LevelsAnalyzed = int64(len(bakings))
+	for _, b := range bakings {
+		if b.DelegateRights < 0 {
+			continue
+		}
+		p, ok := byPriority[b.DelegateRights]
+		if !ok {
+			p = &PriorityRecord{Priority: b.DelegateRights}
+			byPriority[b.DelegateRights] = p
+		}
+		p.Rights++
+		if b.DelegateBaked {
+			p.Baked++
+		}
+		if b.DelegateMissed {
+			p.Missed++
+		}
+		if b.DelegateStole {
+			p.Stolen++
+		}
+	}
+	for _, p := range byPriority {
+		br.Baking = append(br.Baking, *p)
+	}
+	sort.Slice(br.Baking, func(i, j int) bool {
+		return br.Baking[i].Priority < br.Baking[j].Priority
+	})
+	br.TakenBy = storage.GetCycleBlockTakers(baker, cycle)
+	br.StolenFrom = storage.GetCycleStolenFrom(baker, cycle)
+
+	// Endorsing
+	for _, e := range storage.GetCycleEndorsements(baker, cycle) {
+		br.Endorsing.Slots += int64(len(e.Rights))
+		br.Endorsing.Made += int64(len(e.Endorsements))
+		br.Endorsing.Missed += e.Misses
+	}
+
+	// Slashing
+	for _, s := range storage.GetCycleSlashings(cycle) {
+		if s.Offender == baker {
+			br.Slashings = append(br.Slashings, Slashing{Kind: s.Kind, Level: s.Level, Amount: s.Amount})
+		}
+	}
+
+	// Delegations
+	for _, c := range storage.GetCycleDelegationChanges(baker, cycle) {
+		if c.Added {
+			br.Delegations.Added++
+		} else {
+			br.Delegations.Removed++
+		}
+	}
+	br.Delegations.Net = storage.GetNetDelegationChange(baker, cycle)
+
+	// Balances and rewards at the end of the cycle
+	blockID := fmt.Sprint(lastLevel)
+	if constants, err := tzrpc.GetRewardConstants(blockID, cycle); err == nil {
+		c := storage.CompareRewards(baker, cycle, constants)
+		br.Rewards = Rewards{
+			Expected: c.ExpectedBaking + c.ExpectedEndorsing,
+			Earned:   c.EarnedBaking + c.EarnedEndorsing,
+			Lost:     c.Lost,
+			Fees:     c.Fees,
+			Deposits: c.Deposits,
+		}
+	}
+	if balance, err := tzrpc.GetStakingBalanceAt(baker, blockID); err == nil {
+		br.StakingBalance = balance.String()
+	}
+	if delegators, err := tzrpc.GetDelegatedContracts(baker, blockID); err == nil {
+		br.Delegators = len(delegators)
+	}
+	return br
+}
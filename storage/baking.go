@@ -1,5 +1,7 @@
 package storage
 
+import "sync"
+
 // Baking information about a delegate @ level
 type Baking struct {
 	Delegate       string
@@ -11,13 +13,23 @@ type Baking struct {
 	DelegateBaked  bool
 	BlockHash      string
 	Cycle          int64
+	// Baker that actually baked the block, at BakerPriority
+	Baker string
+	// StolenFrom the bakers with higher priority rights, when we stole
+	StolenFrom []string
 }
 
-var bakingStorage []Baking
-var bakingCycleMisses map[int64]int64
+var bakingStorage = map[string][]Baking{}
+var bakingCycleMisses = map[string]map[int64]int64{}
+var bakingCycleTakers = map[string]map[int64]map[string]int64{}
+var bakingCycleVictims = map[string]map[int64]map[string]int64{}
+var bakingTakersWarned = map[string]map[int64]map[string]bool{}
+var bakingLock sync.RWMutex
 
-// RecordBaking in local storage
-func RecordBaking(delegate string, level int64, delegateRights int64, bakerPriority int64, blockHash string) {
+// RecordBaking in local storage for the block at `level` in `cycle`, with the
+// `baker` that actually baked the block and the bakers we stole it from, if
+// any
+func RecordBaking(delegate string, level int64, cycle int64, delegateRights int64, bakerPriority int64, blockHash string, baker string, stolenFrom []string) {
 	b := Baking{
 		Delegate:       delegate,
 		Level:          level,
@@ -27,32 +39,134 @@ func RecordBaking(delegate string, level int64, delegateRights int64, bakerPrior
 		DelegateStole:  bakerPriority == delegateRights && delegateRights > 0,
 		DelegateBaked:  bakerPriority == delegateRights,
 		BlockHash:      blockHash,
-		Cycle:          level / 4096,
+		Cycle:          cycle,
+		Baker:          baker,
+		StolenFrom:     stolenFrom,
 	}
+
+	bakingLock.Lock()
+	defer bakingLock.Unlock()
 	if b.DelegateMissed {
-		bakingCycleMisses[b.Cycle]++
+		if bakingCycleMisses[delegate] == nil {
+			bakingCycleMisses[delegate] = map[int64]int64{}
+		}
+		bakingCycleMisses[delegate][b.Cycle]++
+		countBaker(bakingCycleTakers, delegate, b.Cycle, baker)
+	}
+	if b.DelegateStole {
+		for _, victim := range stolenFrom {
+			countBaker(bakingCycleVictims, delegate, b.Cycle, victim)
+		}
+	}
+	bakingStorage[delegate] = append(bakingStorage[delegate], b)
+}
+
+// countBaker once more for `delegate` in `cycle`.  Callers hold the lock
+func countBaker(counts map[string]map[int64]map[string]int64, delegate string, cycle int64, baker string) {
+	if counts[delegate] == nil {
+		counts[delegate] = map[int64]map[string]int64{}
+	}
+	if counts[delegate][cycle] == nil {
+		counts[delegate][cycle] = map[string]int64{}
 	}
-	bakingStorage = append(bakingStorage, b)
+	counts[delegate][cycle][baker]++
+}
+
+// GetCycleBlockTakers that baked blocks this delegate missed in `cycle`, with
+// how many each took
+func GetCycleBlockTakers(delegate string, cycle int64) map[string]int64 {
+	bakingLock.RLock()
+	defer bakingLock.RUnlock()
+	return copyCounts(bakingCycleTakers[delegate][cycle])
+}
+
+// GetCycleStolenFrom the higher priority bakers this delegate stole blocks
+// from in `cycle`, with how many from each
+func GetCycleStolenFrom(delegate string, cycle int64) map[string]int64 {
+	bakingLock.RLock()
+	defer bakingLock.RUnlock()
+	return copyCounts(bakingCycleVictims[delegate][cycle])
+}
+
+// MarkTakerWarned once we've warned that `taker` keeps baking this delegate's
+// blocks in `cycle`, returning false if we already had
+func MarkTakerWarned(delegate string, cycle int64, taker string) bool {
+	bakingLock.Lock()
+	defer bakingLock.Unlock()
+	if bakingTakersWarned[delegate] == nil {
+		bakingTakersWarned[delegate] = map[int64]map[string]bool{}
+	}
+	if bakingTakersWarned[delegate][cycle] == nil {
+		bakingTakersWarned[delegate][cycle] = map[string]bool{}
+	}
+	if bakingTakersWarned[delegate][cycle][taker] {
+		return false
+	}
+	bakingTakersWarned[delegate][cycle][taker] = true
+	return true
+}
+
+func copyCounts(counts map[string]int64) map[string]int64 {
+	copied := map[string]int64{}
+	for baker, count := range counts {
+		copied[baker] = count
+	}
+	return copied
 }
 
 // GetLastRecordedBakeLevel so we can resume scanning from the returned level+1
 func GetLastRecordedBakeLevel(delegate string) int64 {
-	if len(bakingStorage) == 0 {
+	bakingLock.RLock()
+	defer bakingLock.RUnlock()
+	bakings := bakingStorage[delegate]
+	if len(bakings) == 0 {
 		return -1
 	}
-	return bakingStorage[len(bakingStorage)-1].Level
+	return bakings[len(bakings)-1].Level
+}
+
+// GetBakings returning the `count` most recent
+func GetBakings(delegate string, count int) []Baking {
+	bakingLock.RLock()
+	defer bakingLock.RUnlock()
+	bakings := bakingStorage[delegate]
+	if count > len(bakings) {
+		count = len(bakings)
+	}
+	end := len(bakings)
+	start := end - count
+	// copy so callers can't race with appends to the backing array
+	return append([]Baking{}, bakings[start:end]...)
+}
+
+// GetCycleBakings recorded for this delegate in `cycle`
+func GetCycleBakings(delegate string, cycle int64) []Baking {
+	bakingLock.RLock()
+	defer bakingLock.RUnlock()
+	var bakings []Baking
+	for _, b := range bakingStorage[delegate] {
+		if b.Cycle == cycle {
+			bakings = append(bakings, b)
+		}
+	}
+	return bakings
 }
 
 // GetLatestBakingCycle that this delegate has reported from
 func GetLatestBakingCycle(delegate string) int64 {
-	if len(bakingStorage) == 0 {
+	bakingLock.RLock()
+	defer bakingLock.RUnlock()
+	bakings := bakingStorage[delegate]
+	if len(bakings) == 0 {
 		return -1
 	}
-	return bakingStorage[len(bakingStorage)-1].Cycle
+	return bakings[len(bakings)-1].Cycle
 }
 
 // GetCycleBakeMissCount returns the number of misses in the current cycle
 func GetCycleBakeMissCount(delegate string) (int64, int64) {
 	latestCycle := GetLatestBakingCycle(delegate)
-	return bakingCycleMisses[latestCycle], latestCycle
+	bakingLock.RLock()
+	defer bakingLock.RUnlock()
+	return bakingCycleMisses[delegate][latestCycle], latestCycle
 }
@@ -0,0 +1,88 @@
+package storage
+
+import (
+	"sync"
+
+	"gitlab.com/polychainlabs/tezos-network-monitor/tzrpc"
+)
+
+// Balance snapshots kept per address, about two cycles' worth of levels
+const maxBalanceSnapshots = 8192
+
+// BalanceSnapshot of an address at a level, in tez.  Balance is a baker's
+// full balance, Spendable excludes what it has frozen
+type BalanceSnapshot struct {
+	Address   string
+	Level     int64
+	Cycle     int64
+	Balance   int64
+	Spendable int64
+}
+
+var balanceStorage = map[string][]BalanceSnapshot{}
+var balanceAlertedCycles = map[string]int64{}
+var balanceLock sync.RWMutex
+
+// RecordBalance of `address` at `level`, once per level
+func RecordBalance(address string, level int64, balance int64, spendable int64) {
+	balanceLock.Lock()
+	defer balanceLock.Unlock()
+	snapshots := balanceStorage[address]
+	if len(snapshots) > 0 && snapshots[len(snapshots)-1].Level >= level {
+		return
+	}
+	snapshots = append(snapshots, BalanceSnapshot{
+		Address:   address,
+		Level:     level,
+		Cycle:     tzrpc.Cycle(level),
+		Balance:   balance,
+		Spendable: spendable,
+	})
+	if len(snapshots) > maxBalanceSnapshots {
+		snapshots = snapshots[len(snapshots)-maxBalanceSnapshots:]
+	}
+	balanceStorage[address] = snapshots
+}
+
+// GetBalances returning the `count` most recent snapshots
+func GetBalances(address string, count int) []BalanceSnapshot {
+	balanceLock.RLock()
+	defer balanceLock.RUnlock()
+	snapshots := balanceStorage[address]
+	if count > len(snapshots) {
+		count = len(snapshots)
+	}
+	end := len(snapshots)
+	start := end - count
+	// copy so callers can't race with appends to the backing array
+	return append([]BalanceSnapshot{}, snapshots[start:end]...)
+}
+
+// GetClosingBalance of `address` in `cycle`, the last snapshot recorded
+// during it
+func GetClosingBalance(address string, cycle int64) (BalanceSnapshot, bool) {
+	balanceLock.RLock()
+	defer balanceLock.RUnlock()
+	snapshots := balanceStorage[address]
+	for i := len(snapshots) - 1; i >= 0; i-- {
+		if snapshots[i].Cycle == cycle {
+			return snapshots[i], true
+		}
+		if snapshots[i].Cycle < cycle {
+			break
+		}
+	}
+	return BalanceSnapshot{}, false
+}
+
+// MarkBalanceChangeAlerted for `address` during `cycle`, returning false if
+// it already was
+func MarkBalanceChangeAlerted(address string, cycle int64) bool {
+	balanceLock.Lock()
+	defer balanceLock.Unlock()
+	if alerted, ok := balanceAlertedCycles[address]; ok && alerted == cycle {
+		return false
+	}
+	balanceAlertedCycles[address] = cycle
+	return true
+}
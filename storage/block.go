@@ -1,5 +1,7 @@
 package storage
 
+import "sync"
+
 // Block information to indicate that it's been analyzed
 type Block struct {
 	Level     int64
@@ -7,6 +9,7 @@ type Block struct {
 }
 
 var blockStorage []Block
+var blockLock sync.RWMutex
 
 // RecordBlock in firestore
 func RecordBlock(level int64, blockHash string) {
@@ -14,11 +17,15 @@ func RecordBlock(level int64, blockHash string) {
 		Level:     level,
 		BlockHash: blockHash,
 	}
+	blockLock.Lock()
+	defer blockLock.Unlock()
 	blockStorage = append(blockStorage, b)
 }
 
 // GetLastRecordedBlockLevel so we can resume scanning from the returned level+1
 func GetLastRecordedBlockLevel() int64 {
+	blockLock.RLock()
+	defer blockLock.RUnlock()
 	if len(blockStorage) == 0 {
 		return -1
 	}
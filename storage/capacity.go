@@ -0,0 +1,50 @@
+package storage
+
+import (
+	"sync"
+)
+
+// Capacity states, from least to most delegated
+const (
+	CapacityOK          = "ok"
+	CapacityApproaching = "approaching"
+	CapacityOver        = "over"
+)
+
+// Capacity of a baker to accept delegations, in mutez
+type Capacity struct {
+	Delegate       string
+	Level          int64
+	Balance        int64
+	FrozenBalance  int64
+	StakingBalance int64
+	// Ratio of staking balance to own balance the protocol allows
+	Ratio float64
+	// Capacity is the most staking balance our own balance can cover, from
+	// our frozen balance alone once it has to be staked
+	Capacity int64
+	// Headroom before we're over capacity, negative once we are
+	Headroom    int64
+	Utilization float64
+	State       string
+}
+
+var capacityStorage = map[string]Capacity{}
+var capacityLock sync.RWMutex
+
+// RecordCapacity of a baker, returning what was recorded before, if anything
+func RecordCapacity(c Capacity) (Capacity, bool) {
+	capacityLock.Lock()
+	defer capacityLock.Unlock()
+	previous, ok := capacityStorage[c.Delegate]
+	capacityStorage[c.Delegate] = c
+	return previous, ok
+}
+
+// GetCapacity last recorded for this baker
+func GetCapacity(delegate string) (Capacity, bool) {
+	capacityLock.RLock()
+	defer capacityLock.RUnlock()
+	c, ok := capacityStorage[delegate]
+	return c, ok
+}
@@ -0,0 +1,28 @@
+package storage
+
+import (
+	"sync"
+
+	"gitlab.com/polychainlabs/tezos-network-monitor/tzrpc"
+)
+
+var consensusKeyStorage = map[string]tzrpc.ConsensusKeys{}
+var consensusKeyLock sync.RWMutex
+
+// RecordConsensusKeys of a delegate, returning what was recorded before, if
+// anything
+func RecordConsensusKeys(delegate string, keys tzrpc.ConsensusKeys) (tzrpc.ConsensusKeys, bool) {
+	consensusKeyLock.Lock()
+	defer consensusKeyLock.Unlock()
+	previous, ok := consensusKeyStorage[delegate]
+	consensusKeyStorage[delegate] = keys
+	return previous, ok
+}
+
+// GetConsensusKeys last recorded for this delegate
+func GetConsensusKeys(delegate string) (tzrpc.ConsensusKeys, bool) {
+	consensusKeyLock.RLock()
+	defer consensusKeyLock.RUnlock()
+	keys, ok := consensusKeyStorage[delegate]
+	return keys, ok
+}
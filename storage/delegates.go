@@ -0,0 +1,55 @@
+package storage
+
+import (
+	"sync"
+)
+
+// Activity of a delegate as last seen on chain
+type Activity struct {
+	Delegate    string
+	Cycle       int64
+	Deactivated bool
+	GracePeriod int64
+	// WarnedCycle we last warned about deactivation in, or -1
+	WarnedCycle int64
+}
+
+var activityStorage = map[string]Activity{}
+var activityLock sync.RWMutex
+
+// RecordActivity of a delegate seen during `cycle`, returning what was
+// recorded before, if anything
+func RecordActivity(delegate string, cycle int64, deactivated bool, gracePeriod int64) (Activity, bool) {
+	activityLock.Lock()
+	defer activityLock.Unlock()
+	previous, ok := activityStorage[delegate]
+	a := Activity{
+		Delegate:    delegate,
+		Cycle:       cycle,
+		Deactivated: deactivated,
+		GracePeriod: gracePeriod,
+		WarnedCycle: -1,
+	}
+	if ok {
+		a.WarnedCycle = previous.WarnedCycle
+	}
+	activityStorage[delegate] = a
+	return previous, ok
+}
+
+// MarkActivityWarned once we've warned about deactivation during `cycle`
+func MarkActivityWarned(delegate string, cycle int64) {
+	activityLock.Lock()
+	defer activityLock.Unlock()
+	a := activityStorage[delegate]
+	a.WarnedCycle = cycle
+	activityStorage[delegate] = a
+}
+
+// GetActivity last recorded for this delegate
+func GetActivity(delegate string) (Activity, bool) {
+	activityLock.RLock()
+	defer activityLock.RUnlock()
+	a, ok := activityStorage[delegate]
+	return a, ok
+}
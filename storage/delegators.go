@@ -0,0 +1,99 @@
+package storage
+
+import (
+	"sync"
+
+	"gitlab.com/polychainlabs/tezos-network-monitor/tzrpc"
+)
+
+// DelegationChange of a contract joining or leaving one of our bakers, with
+// its balance in tez when we noticed
+type DelegationChange struct {
+	Baker    string
+	Contract string
+	Level    int64
+	Cycle    int64
+	Balance  int64
+	Added    bool
+}
+
+var delegatorStorage = map[string]map[string]bool{}
+var delegationChanges = map[string][]DelegationChange{}
+var delegatorLock sync.RWMutex
+
+// RecordDelegators of `baker`, returning the contracts added and
+// removed since last time.  Nothing is added or removed the first time
+func RecordDelegators(baker string, contracts []string) ([]string, []string) {
+	current := map[string]bool{}
+	for _, c := range contracts {
+		current[c] = true
+	}
+
+	delegatorLock.Lock()
+	defer delegatorLock.Unlock()
+	previous, ok := delegatorStorage[baker]
+	delegatorStorage[baker] = current
+	if !ok {
+		return nil, nil
+	}
+
+	var added, removed []string
+	for c := range current {
+		if !previous[c] {
+			added = append(added, c)
+		}
+	}
+	for c := range previous {
+		if !current[c] {
+			removed = append(removed, c)
+		}
+	}
+	return added, removed
+}
+
+// RecordDelegationChange of `contract` joining or leaving `baker` at `level`
+func RecordDelegationChange(baker string, contract string, level int64, balance int64, added bool) {
+	delegatorLock.Lock()
+	defer delegatorLock.Unlock()
+	delegationChanges[baker] = append(delegationChanges[baker], DelegationChange{
+		Baker:    baker,
+		Contract: contract,
+		Level:    level,
+		Cycle:    tzrpc.Cycle(level),
+		Balance:  balance,
+		Added:    added,
+	})
+}
+
+// GetDelegatorCount of `baker` as last recorded
+func GetDelegatorCount(baker string) int {
+	delegatorLock.RLock()
+	defer delegatorLock.RUnlock()
+	return len(delegatorStorage[baker])
+}
+
+// GetCycleDelegationChanges of `baker` during `cycle`
+func GetCycleDelegationChanges(baker string, cycle int64) []DelegationChange {
+	delegatorLock.RLock()
+	defer delegatorLock.RUnlock()
+	var changes []DelegationChange
+	for _, c := range delegationChanges[baker] {
+		if c.Cycle == cycle {
+			changes = append(changes, c)
+		}
+	}
+	return changes
+}
+
+// GetNetDelegationChange of `baker` during `cycle`, in tez
+func GetNetDelegationChange(baker string, cycle int64) int64 {
+	net := int64(0)
+	for _, c := range GetCycleDelegationChanges(baker, cycle) {
+		if c.Added {
+			net += c.Balance
+		} else {
+			net -= c.Balance
+		}
+	}
+	return net
+}
@@ -1,5 +1,7 @@
 package storage
 
+import "sync"
+
 // Endorsement information for a specific level
 type Endorsement struct {
 	Delegate     string
@@ -11,11 +13,12 @@ type Endorsement struct {
 	Cycle        int64
 }
 
-var endorsementStorage []Endorsement
-var endorsementCycleMisses map[int64]int64
+var endorsementStorage = map[string][]Endorsement{}
+var endorsementCycleMisses = map[string]map[int64]int64{}
+var endorsementLock sync.RWMutex
 
-// RecordEndorsement in firestore
-func RecordEndorsement(delegate string, level int64, rights []int64, endorsements []int64, block string) {
+// RecordEndorsement of `level` in `cycle`
+func RecordEndorsement(delegate string, level int64, cycle int64, rights []int64, endorsements []int64, block string) {
 	e := Endorsement{
 		Delegate:     delegate,
 		Level:        level,
@@ -23,39 +26,74 @@ func RecordEndorsement(delegate string, level int64, rights []int64, endorsement
 		Endorsements: endorsements,
 		Misses:       int64(len(rights) - len(endorsements)),
 		Block:        block,
-		Cycle:        level / 4096,
+		Cycle:        cycle,
+	}
+
+	endorsementLock.Lock()
+	defer endorsementLock.Unlock()
+	if e.Misses > 0 {
+		if endorsementCycleMisses[delegate] == nil {
+			endorsementCycleMisses[delegate] = map[int64]int64{}
+		}
+		endorsementCycleMisses[delegate][e.Cycle]++
 	}
-	endorsementStorage = append(endorsementStorage, e)
+	endorsementStorage[delegate] = append(endorsementStorage[delegate], e)
 }
 
 // GetLastRecordedEndorsementLevel so we can resume scanning from the returned level+1
 func GetLastRecordedEndorsementLevel(delegate string) int64 {
-	if len(endorsementStorage) == 0 {
+	endorsementLock.RLock()
+	defer endorsementLock.RUnlock()
+	endorsements := endorsementStorage[delegate]
+	if len(endorsements) == 0 {
 		return -1
 	}
-	return endorsementStorage[len(endorsementStorage)-1].Level
+	return endorsements[len(endorsements)-1].Level
 }
 
 // GetEndorsements returning the `count` most recent
 func GetEndorsements(delegate string, count int) []Endorsement {
-	if count > len(endorsementStorage) {
-		count = len(endorsementStorage)
+	endorsementLock.RLock()
+	defer endorsementLock.RUnlock()
+	endorsements := endorsementStorage[delegate]
+	if count > len(endorsements) {
+		count = len(endorsements)
 	}
-	end := len(endorsementStorage)
+	end := len(endorsements)
 	start := end - count
-	return endorsementStorage[start:end]
+	// copy so callers can't race with appends to the backing array
+	return append([]Endorsement{}, endorsements[start:end]...)
+}
+
+// GetCycleEndorsements recorded for this delegate in `cycle`
+func GetCycleEndorsements(delegate string, cycle int64) []Endorsement {
+	endorsementLock.RLock()
+	defer endorsementLock.RUnlock()
+	var endorsements []Endorsement
+	for _, e := range endorsementStorage[delegate] {
+		if e.Cycle == cycle {
+			endorsements = append(endorsements, e)
+		}
+	}
+	return endorsements
 }
 
 // GetLatestEndorsementCycle that this delegate has reported from
 func GetLatestEndorsementCycle(delegate string) int64 {
-	if len(endorsementStorage) == 0 {
+	endorsementLock.RLock()
+	defer endorsementLock.RUnlock()
+	endorsements := endorsementStorage[delegate]
+	if len(endorsements) == 0 {
 		return -1
 	}
-	return endorsementStorage[len(endorsementStorage)-1].Cycle
+	return endorsements[len(endorsements)-1].Cycle
 }
 
-// GetCycleEndorsementMissCount ...
+// GetCycleEndorsementMissCount returns the number of levels with missed
+// endorsements in the current cycle
 func GetCycleEndorsementMissCount(delegate string) int64 {
 	latestCycle := GetLatestEndorsementCycle(delegate)
-	return endorsementCycleMisses[latestCycle]
+	endorsementLock.RLock()
+	defer endorsementLock.RUnlock()
+	return endorsementCycleMisses[delegate][latestCycle]
 }
@@ -0,0 +1,60 @@
+package storage
+
+import (
+	"sync"
+)
+
+// CycleForecast of a baker's rights and deposits for an upcoming cycle, or
+// what's left of the current one, in mutez
+type CycleForecast struct {
+	Cycle            int64
+	Blocks           int64
+	EndorsementSlots int64
+	Deposits         int64
+	// Available spendable balance once this cycle's deposits are frozen,
+	// counting deposits and rewards released in the meantime.  Negative if
+	// we'd run short
+	Available int64
+}
+
+// Forecast of a baker's upcoming cycles
+type Forecast struct {
+	Delegate  string
+	Level     int64
+	Spendable int64
+	Cycles    []CycleForecast
+	// WarnedCycle we last alerted on a shortfall in, or -1
+	WarnedCycle int64
+}
+
+var forecastStorage = map[string]Forecast{}
+var forecastLock sync.RWMutex
+
+// RecordForecast of a baker, keeping the cycle we last alerted in
+func RecordForecast(f Forecast) Forecast {
+	forecastLock.Lock()
+	defer forecastLock.Unlock()
+	f.WarnedCycle = -1
+	if previous, ok := forecastStorage[f.Delegate]; ok {
+		f.WarnedCycle = previous.WarnedCycle
+	}
+	forecastStorage[f.Delegate] = f
+	return f
+}
+
+// MarkForecastWarned once we've alerted on a shortfall during `cycle`
+func MarkForecastWarned(delegate string, cycle int64) {
+	forecastLock.Lock()
+	defer forecastLock.Unlock()
+	f := forecastStorage[delegate]
+	f.WarnedCycle = cycle
+	forecastStorage[delegate] = f
+}
+
+// GetForecast last recorded for this baker
+func GetForecast(delegate string) (Forecast, bool) {
+	forecastLock.RLock()
+	defer forecastLock.RUnlock()
+	f, ok := forecastStorage[delegate]
+	return f, ok
+}
@@ -0,0 +1,68 @@
+package storage
+
+import (
+	"sync"
+)
+
+// Vote cast by one of our bakers
+type Vote struct {
+	Baker     string
+	Level     int64
+	Period    int64
+	Kind      string
+	Proposals []string
+	Ballot    string
+}
+
+var voteStorage = map[string][]Vote{}
+var votingPeriod int64 = -1
+var governanceWarnings = map[string]int64{}
+var governanceLock sync.RWMutex
+
+// RecordVote by `baker` found in the block at `level`
+func RecordVote(baker string, level int64, period int64, kind string, proposals []string, ballot string) {
+	governanceLock.Lock()
+	defer governanceLock.Unlock()
+	voteStorage[baker] = append(voteStorage[baker], Vote{
+		Baker:     baker,
+		Level:     level,
+		Period:    period,
+		Kind:      kind,
+		Proposals: proposals,
+		Ballot:    ballot,
+	})
+}
+
+// GetPeriodVotes cast by `baker` during voting period `period`
+func GetPeriodVotes(baker string, period int64) []Vote {
+	governanceLock.RLock()
+	defer governanceLock.RUnlock()
+	var votes []Vote
+	for _, v := range voteStorage[baker] {
+		if v.Period == period {
+			votes = append(votes, v)
+		}
+	}
+	return votes
+}
+
+// RecordVotingPeriod the chain is in, returning the one recorded before or -1
+func RecordVotingPeriod(period int64) int64 {
+	governanceLock.Lock()
+	defer governanceLock.Unlock()
+	previous := votingPeriod
+	votingPeriod = period
+	return previous
+}
+
+// MarkGovernanceWarned once we've warned `baker` hasn't voted in `period`,
+// returning false if we already had
+func MarkGovernanceWarned(baker string, period int64) bool {
+	governanceLock.Lock()
+	defer governanceLock.Unlock()
+	if warned, ok := governanceWarnings[baker]; ok && warned == period {
+		return false
+	}
+	governanceWarnings[baker] = period
+	return true
+}
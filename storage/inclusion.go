@@ -0,0 +1,136 @@
+package storage
+
+import (
+	"sync"
+)
+
+// Reasons an endorsement was missed
+const (
+	MissNeverBroadcast = "endorsement never broadcast"
+	MissRefused        = "endorsement refused by the mempool"
+	MissNotIncluded    = "endorsement broadcast but not included"
+	MissUnknown        = "mempool not watched"
+)
+
+// Mempool statuses of an endorsement that explain why it wasn't included
+var refusedStatuses = []string{"refused", "outdated", "branch_refused", "branch_delayed"}
+
+// Levels of inclusion records kept per delegate
+const maxInclusionLevels = 16384
+
+// Inclusion of a delegate's endorsement for `Level`, and what the mempool saw
+// of it
+type Inclusion struct {
+	Delegate string
+	Level    int64
+	// IncludedLevel of the block the endorsement was included in, or 0
+	IncludedLevel int64
+	// IncludedBy the baker of that block
+	IncludedBy string
+	// MempoolStatuses the endorsement was seen with, in order
+	MempoolStatuses []string
+	MempoolErrors   []string
+}
+
+// Latency in levels between the endorsed level and its inclusion, 1 when
+// included in the next block, or 0 if it wasn't included
+func (i Inclusion) Latency() int64 {
+	if i.IncludedLevel == 0 {
+		return 0
+	}
+	return i.IncludedLevel - i.Level
+}
+
+var inclusionStorage = map[string]map[int64]*Inclusion{}
+
+// mempoolLevels the mempool was seen with endorsements for
+var mempoolLevels = map[int64]bool{}
+var inclusionLock sync.RWMutex
+
+// inclusion record for `delegate` at `level`, created if need be.  Callers
+// hold the lock
+func inclusion(delegate string, level int64) *Inclusion {
+	if inclusionStorage[delegate] == nil {
+		inclusionStorage[delegate] = map[int64]*Inclusion{}
+	}
+	i, ok := inclusionStorage[delegate][level]
+	if !ok {
+		i = &Inclusion{Delegate: delegate, Level: level}
+		inclusionStorage[delegate][level] = i
+		delete(inclusionStorage[delegate], level-maxInclusionLevels)
+	}
+	return i
+}
+
+// RecordInclusion of the delegate's endorsement for `level` in the block at
+// `includedLevel` baked by `includedBy`, returning false if it was already
+// included
+func RecordInclusion(delegate string, level int64, includedLevel int64, includedBy string) bool {
+	inclusionLock.Lock()
+	defer inclusionLock.Unlock()
+	i := inclusion(delegate, level)
+	if i.IncludedLevel != 0 {
+		return false
+	}
+	i.IncludedLevel = includedLevel
+	i.IncludedBy = includedBy
+	return true
+}
+
+// RecordMempoolEndorsement of the delegate for `level` seen in the mempool
+// with `status`
+func RecordMempoolEndorsement(delegate string, level int64, status string, errors []string) {
+	inclusionLock.Lock()
+	defer inclusionLock.Unlock()
+	i := inclusion(delegate, level)
+	i.MempoolStatuses = append(i.MempoolStatuses, status)
+	i.MempoolErrors = append(i.MempoolErrors, errors...)
+}
+
+// MarkMempoolWatched at `level`, once the mempool has been seen with
+// endorsements for it.  Levels we weren't watching, e.g. while the stream was
+// down, can't explain a miss
+func MarkMempoolWatched(level int64) {
+	inclusionLock.Lock()
+	defer inclusionLock.Unlock()
+	if !mempoolLevels[level] {
+		mempoolLevels[level] = true
+		delete(mempoolLevels, level-maxInclusionLevels)
+	}
+}
+
+// GetInclusion recorded for the delegate's endorsement at `level`
+func GetInclusion(delegate string, level int64) (Inclusion, bool) {
+	inclusionLock.RLock()
+	defer inclusionLock.RUnlock()
+	i, ok := inclusionStorage[delegate][level]
+	if !ok {
+		return Inclusion{Delegate: delegate, Level: level}, false
+	}
+	return *i, true
+}
+
+// MissReason of the delegate's endorsement at `level`, from what the mempool
+// saw of it.  It's explained as soon as the level is missed, so an endorsement
+// included later is reported on its own
+func MissReason(delegate string, level int64) string {
+	i, _ := GetInclusion(delegate, level)
+	inclusionLock.RLock()
+	watched := mempoolLevels[level]
+	inclusionLock.RUnlock()
+
+	switch {
+	case len(i.MempoolStatuses) == 0 && !watched:
+		return MissUnknown
+	case len(i.MempoolStatuses) == 0:
+		return MissNeverBroadcast
+	}
+	for j := len(i.MempoolStatuses) - 1; j >= 0; j-- {
+		for _, s := range refusedStatuses {
+			if i.MempoolStatuses[j] == s {
+				return MissRefused
+			}
+		}
+	}
+	return MissNotIncluded
+}
@@ -0,0 +1,35 @@
+package storage
+
+import (
+	"sync"
+)
+
+// Pending operations remembered, so each is only inspected once per status
+const maxPendingSeen = 50000
+
+type pendingKey struct {
+	hash   string
+	status string
+}
+
+var pendingSeen = map[pendingKey]bool{}
+var pendingOrder []pendingKey
+var pendingLock sync.Mutex
+
+// MarkPendingSeen operation `hash` with `status`, returning false if it
+// already was.  The oldest are forgotten past a limit
+func MarkPendingSeen(hash string, status string) bool {
+	pendingLock.Lock()
+	defer pendingLock.Unlock()
+	key := pendingKey{hash, status}
+	if pendingSeen[key] {
+		return false
+	}
+	pendingSeen[key] = true
+	pendingOrder = append(pendingOrder, key)
+	if len(pendingOrder) > maxPendingSeen {
+		delete(pendingSeen, pendingOrder[0])
+		pendingOrder = pendingOrder[1:]
+	}
+	return true
+}
@@ -0,0 +1,99 @@
+package storage
+
+import (
+	"sort"
+	"sync"
+
+	"gitlab.com/polychainlabs/tezos-network-monitor/tzrpc"
+)
+
+// Nonce committed to by one of our bakers in a block it baked
+type Nonce struct {
+	Delegate string
+	Level    int64
+	Cycle    int64
+	Hash     string
+	// RevealedLevel of the block that revealed this nonce, or -1
+	RevealedLevel int64
+	Warned        bool
+	Forfeited     bool
+}
+
+var nonceStorage = map[int64]*Nonce{}
+var nonceLock sync.RWMutex
+
+// RecordNonceCommitment by `delegate` in the block it baked at `level`
+func RecordNonceCommitment(delegate string, level int64, hash string) {
+	nonceLock.Lock()
+	defer nonceLock.Unlock()
+	if _, ok := nonceStorage[level]; ok {
+		return
+	}
+	nonceStorage[level] = &Nonce{
+		Delegate:      delegate,
+		Level:         level,
+		Cycle:         tzrpc.Cycle(level),
+		Hash:          hash,
+		RevealedLevel: -1,
+	}
+}
+
+// RecordNonceRevelation of the nonce committed at `level`, in the block at
+// `revealedLevel`
+func RecordNonceRevelation(level int64, revealedLevel int64) {
+	nonceLock.Lock()
+	defer nonceLock.Unlock()
+	if n, ok := nonceStorage[level]; ok {
+		n.RevealedLevel = revealedLevel
+	}
+}
+
+// MarkNonceWarned once we've alerted that the nonce at `level` is unrevealed
+func MarkNonceWarned(level int64) {
+	nonceLock.Lock()
+	defer nonceLock.Unlock()
+	if n, ok := nonceStorage[level]; ok {
+		n.Warned = true
+	}
+}
+
+// MarkNonceForfeited once its revelation window closed without one
+func MarkNonceForfeited(level int64) {
+	nonceLock.Lock()
+	defer nonceLock.Unlock()
+	if n, ok := nonceStorage[level]; ok {
+		n.Forfeited = true
+	}
+}
+
+// GetUnrevealedNonces still waiting on a revelation, oldest first
+func GetUnrevealedNonces() []Nonce {
+	nonceLock.RLock()
+	defer nonceLock.RUnlock()
+	nonces := []Nonce{}
+	for _, n := range nonceStorage {
+		if n.RevealedLevel < 0 && !n.Forfeited {
+			nonces = append(nonces, *n)
+		}
+	}
+	sort.Slice(nonces, func(i, j int) bool {
+		return nonces[i].Level < nonces[j].Level
+	})
+	return nonces
+}
+
+// GetCycleNonces committed to by this delegate in `cycle`, oldest first
+func GetCycleNonces(delegate string, cycle int64) []Nonce {
+	nonceLock.RLock()
+	defer nonceLock.RUnlock()
+	nonces := []Nonce{}
+	for _, n := range nonceStorage {
+		if n.Delegate == delegate && n.Cycle == cycle {
+			nonces = append(nonces, *n)
+		}
+	}
+	sort.Slice(nonces, func(i, j int) bool {
+		return nonces[i].Level < nonces[j].Level
+	})
+	return nonces
+}
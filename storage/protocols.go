@@ -0,0 +1,83 @@
+package storage
+
+import (
+	"sync"
+)
+
+// ProtocolRange of consecutive processed levels validated with one protocol
+type ProtocolRange struct {
+	Protocol   string
+	FirstLevel int64
+	LastLevel  int64
+	Supported  bool
+}
+
+var protocolStorage []ProtocolRange
+var announcedProtocols = map[string]bool{}
+var protocolLock sync.RWMutex
+
+// RecordBlockProtocol of the block at `level`
+func RecordBlockProtocol(level int64, protocol string, supported bool) {
+	protocolLock.Lock()
+	defer protocolLock.Unlock()
+	if n := len(protocolStorage); n > 0 {
+		last := &protocolStorage[n-1]
+		if last.Protocol == protocol && level >= last.FirstLevel && level <= last.LastLevel+1 {
+			if level > last.LastLevel {
+				last.LastLevel = level
+			}
+			return
+		}
+	}
+	protocolStorage = append(protocolStorage, ProtocolRange{
+		Protocol:   protocol,
+		FirstLevel: level,
+		LastLevel:  level,
+		Supported:  supported,
+	})
+}
+
+// GetBlockProtocol of the block at `level`, or "" if it wasn't processed
+func GetBlockProtocol(level int64) string {
+	protocolLock.RLock()
+	defer protocolLock.RUnlock()
+	for i := len(protocolStorage) - 1; i >= 0; i-- {
+		r := protocolStorage[i]
+		if level >= r.FirstLevel && level <= r.LastLevel {
+			return r.Protocol
+		}
+	}
+	return ""
+}
+
+// GetProtocols of processed blocks, in the order they were processed
+func GetProtocols() []ProtocolRange {
+	protocolLock.RLock()
+	defer protocolLock.RUnlock()
+	return append([]ProtocolRange{}, protocolStorage...)
+}
+
+// GetUnsupportedProtocols of processed blocks, whose levels were skipped by
+// every analyzer and need backfilling once the monitor supports them
+func GetUnsupportedProtocols() []ProtocolRange {
+	protocolLock.RLock()
+	defer protocolLock.RUnlock()
+	unsupported := []ProtocolRange{}
+	for _, r := range protocolStorage {
+		if !r.Supported {
+			unsupported = append(unsupported, r)
+		}
+	}
+	return unsupported
+}
+
+// MarkProtocolAnnounced under `key`, returning false if it already was
+func MarkProtocolAnnounced(key string) bool {
+	protocolLock.Lock()
+	defer protocolLock.Unlock()
+	if announcedProtocols[key] {
+		return false
+	}
+	announcedProtocols[key] = true
+	return true
+}
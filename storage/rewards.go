@@ -0,0 +1,97 @@
+package storage
+
+import (
+	"sync"
+
+	"gitlab.com/polychainlabs/tezos-network-monitor/tzrpc"
+)
+
+// Categories of income and frozen balance we attribute to a baker
+const (
+	BakingRewards       = "baking_rewards"
+	EndorsementRewards  = "endorsement_rewards"
+	RevelationRewards   = "revelation_rewards"
+	AccusationRewards   = "accusation_rewards"
+	Fees                = "fees"
+	BakingDeposits      = "baking_deposits"
+	EndorsementDeposits = "endorsement_deposits"
+	UnfrozenDeposits    = "unfrozen_deposits"
+	UnfrozenRewards     = "unfrozen_rewards"
+	UnfrozenFees        = "unfrozen_fees"
+	Slashed             = "slashed"
+)
+
+// RewardComparison of what a baker earned over a cycle against the most its
+// rights could have earned, in mutez
+type RewardComparison struct {
+	Delegate          string
+	Cycle             int64
+	ExpectedBaking    int64
+	EarnedBaking      int64
+	ExpectedEndorsing int64
+	EarnedEndorsing   int64
+	Fees              int64
+	Deposits          int64
+	Slashed           int64
+	// Lost income, never below 0 even if we stole blocks
+	Lost int64
+	// Totals per category
+	Categories map[string]int64
+}
+
+var rewardStorage = map[string]map[int64]map[string]int64{}
+var rewardLock sync.RWMutex
+
+// RecordReward of `amount` mutez in `category` for `cycle`.  Amounts add up
+func RecordReward(delegate string, cycle int64, category string, amount int64) {
+	rewardLock.Lock()
+	defer rewardLock.Unlock()
+	if rewardStorage[delegate] == nil {
+		rewardStorage[delegate] = map[int64]map[string]int64{}
+	}
+	if rewardStorage[delegate][cycle] == nil {
+		rewardStorage[delegate][cycle] = map[string]int64{}
+	}
+	rewardStorage[delegate][cycle][category] += amount
+}
+
+// GetCycleRewards recorded for this delegate in `cycle`, by category
+func GetCycleRewards(delegate string, cycle int64) map[string]int64 {
+	rewardLock.RLock()
+	defer rewardLock.RUnlock()
+	rewards := map[string]int64{}
+	for category, amount := range rewardStorage[delegate][cycle] {
+		rewards[category] = amount
+	}
+	return rewards
+}
+
+// CompareRewards earned by this delegate in `cycle` with what its recorded
+// rights would have earned had every block been baked at priority 0 and
+// fully endorsed
+func CompareRewards(delegate string, cycle int64, constants *tzrpc.Constants) RewardComparison {
+	rewards := GetCycleRewards(delegate, cycle)
+	c := RewardComparison{
+		Delegate:        delegate,
+		Cycle:           cycle,
+		EarnedBaking:    rewards[BakingRewards],
+		EarnedEndorsing: rewards[EndorsementRewards],
+		Fees:            rewards[Fees],
+		Deposits:        rewards[BakingDeposits] + rewards[EndorsementDeposits],
+		Slashed:         rewards[Slashed],
+		Categories:      rewards,
+	}
+	for _, b := range GetCycleBakings(delegate, cycle) {
+		if b.DelegateRights == 0 {
+			c.ExpectedBaking += constants.MaxBakingReward()
+		}
+	}
+	for _, e := range GetCycleEndorsements(delegate, cycle) {
+		c.ExpectedEndorsing += int64(len(e.Rights)) * constants.MaxEndorsementReward()
+	}
+	c.Lost = c.ExpectedBaking + c.ExpectedEndorsing - c.EarnedBaking - c.EarnedEndorsing
+	if c.Lost < 0 {
+		c.Lost = 0
+	}
+	return c
+}
@@ -0,0 +1,62 @@
+package storage
+
+import (
+	"sync"
+	"time"
+)
+
+// Slashing event found on chain, for anyone on the network
+type Slashing struct {
+	Kind     string
+	Offender string
+	Level    int64
+	Cycle    int64
+	// Amount slashed in mutez, as a positive number
+	Amount   int64
+	Ours     bool
+	Recorded time.Time
+}
+
+var slashingStorage []Slashing
+var slashingLock sync.RWMutex
+
+// RecordSlashing of `offender` found in the block at `level` in `cycle`
+func RecordSlashing(kind string, offender string, level int64, cycle int64, amount int64, ours bool) {
+	s := Slashing{
+		Kind:     kind,
+		Offender: offender,
+		Level:    level,
+		Cycle:    cycle,
+		Amount:   amount,
+		Ours:     ours,
+		Recorded: time.Now(),
+	}
+	slashingLock.Lock()
+	defer slashingLock.Unlock()
+	slashingStorage = append(slashingStorage, s)
+}
+
+// GetSlashings returning the `count` most recent
+func GetSlashings(count int) []Slashing {
+	slashingLock.RLock()
+	defer slashingLock.RUnlock()
+	if count > len(slashingStorage) {
+		count = len(slashingStorage)
+	}
+	// copy so callers can't race with appends to the backing array
+	start := len(slashingStorage) - count
+	return append([]Slashing{}, slashingStorage[start:]...)
+}
+
+// GetCycleSlashings found in blocks of `cycle`
+func GetCycleSlashings(cycle int64) []Slashing {
+	slashingLock.RLock()
+	defer slashingLock.RUnlock()
+	var slashings []Slashing
+	for _, s := range slashingStorage {
+		if s.Cycle == cycle {
+			slashings = append(slashings, s)
+		}
+	}
+	return slashings
+}
@@ -1,12 +1,7 @@
 package tzrpc
 
 import (
-	"encoding/json"
 	"fmt"
-	"io/ioutil"
-	"log"
-	"net/http"
-	"os"
 )
 
 // BakingRights on the protocol
@@ -14,40 +9,35 @@ type BakingRights struct {
 	data []map[string]interface{}
 }
 
-// GetBakingRights from the network
+// GetBakingRights from the network, in the context of the block at `level` if
+// it exists so archived levels can be queried too, otherwise the head
 // Schema defined here: https://tezos.gitlab.io/alphanet/api/rpc.html#get-block-id-helpers-baking-rights
 func GetBakingRights(level int64) (*BakingRights, error) {
-	// Get Payload
-	resp, err := http.Get(fmt.Sprintf("%v/chains/main/blocks/head/helpers/baking_rights?level=%v", os.Getenv("NODE_URL"), level))
-	if err != nil {
-		log.Println("[Baking Rights] Unable to query endpoint")
-		return nil, err
-	}
-
-	defer resp.Body.Close()
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		log.Println("[Baking Rights] Unable to read response")
-		return nil, err
+	if cached, ok := bakingRightsCache.get(level); ok {
+		if err, ok := cached.(error); ok {
+			return nil, err
+		}
+		return cached.(*BakingRights), nil
 	}
 
-	// Parse Body
-	er := BakingRights{}
-	err = json.Unmarshal(body, &er.data)
+	br := BakingRights{}
+	err := getRights("baking_rights", fmt.Sprintf("chains/main/blocks/%v/helpers/baking_rights?level=%v", rightsContext(level), level), &br.data)
 	if err != nil {
-		log.Println("[Baking Rights] Unable to parse json payload")
+		bakingRightsCache.add(level, err, rightsRetry)
 		return nil, err
 	}
 
-	return &er, nil
+	bakingRightsCache.add(level, &br, 0)
+	return &br, nil
 }
 
-// GetBakingPriority of this delegate at this level
+// GetBakingPriority of this delegate at this level, its round on Tenderbake
+// protocols
 func (br *BakingRights) GetBakingPriority(delegate string) int64 {
 	priority := int64(-1)
 	for _, rights := range br.data {
-		if rights["delegate"] == delegate {
-			priority = int64(rights["priority"].(float64))
+		if matchesDelegate(rights, delegate) {
+			priority = rightsRound(rights)
 		}
 	}
 	return priority
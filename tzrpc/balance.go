@@ -4,10 +4,8 @@ import (
 	"errors"
 	"fmt"
 	"io/ioutil"
-	"log"
 	"math/big"
 	"net/http"
-	"os"
 	"strings"
 )
 
@@ -15,27 +13,58 @@ import (
 // amount is in *full* Tez. Schema defined here:
 // https://tezos.gitlab.io/alphanet/api/rpc.html#get-block-id-context-contracts-contract-id-balance
 func GetBalance(pkh string) (*big.Int, error) {
-	// Get Payload
-	var path string
-	if len(pkh) > 2 && strings.HasPrefix(pkh, "KT1") {
-		path = "chains/main/blocks/head/context/contracts"
-	} else if len(pkh) > 1 && strings.HasPrefix(pkh, "tz") {
-		path = "chains/main/blocks/head/context/delegates"
-	} else {
+	return GetBalanceAt(pkh, "head")
+}
+
+// GetBalanceAt the block with `blockID`, a hash, level or `head`.  A baker's
+// is its full balance, including frozen deposits, fees and rewards.  Ithaca
+// renamed it `full_balance`, and addresses that aren't bakers fall back to
+// their contract's balance
+func GetBalanceAt(pkh string, blockID string) (*big.Int, error) {
+	if strings.HasPrefix(pkh, "KT1") {
+		return getBalance(blockID, "contracts", pkh, "balance")
+	}
+	if !strings.HasPrefix(pkh, "tz") {
+		return nil, errors.New("Invalid pkh format")
+	}
+	balance, err := getBalance(blockID, "delegates", pkh, "balance")
+	if err == errNotFound {
+		balance, err = getBalance(blockID, "delegates", pkh, "full_balance")
+	}
+	if err == errNotFound {
+		balance, err = getBalance(blockID, "contracts", pkh, "balance")
+	}
+	return balance, err
+}
+
+// GetSpendableBalanceAt the block with `blockID`, a hash, level or `head`.
+// This excludes a baker's frozen deposits, fees and rewards
+func GetSpendableBalanceAt(pkh string, blockID string) (*big.Int, error) {
+	if !strings.HasPrefix(pkh, "KT1") && !strings.HasPrefix(pkh, "tz") {
 		return nil, errors.New("Invalid pkh format")
 	}
-	resp, err := http.Get(fmt.Sprintf("%v/%v/%v/balance", os.Getenv("NODE_URL"), path, pkh))
+	return getBalance(blockID, "contracts", pkh, "balance")
+}
+
+// getBalance in tez from the `field` of `pkh` under `kind`, `contracts` or
+// `delegates`
+func getBalance(blockID string, kind string, pkh string, field string) (*big.Int, error) {
+	// Get Payload
+	resp, err := get(http.DefaultClient, "balance", fmt.Sprintf("chains/main/blocks/%v/context/%v/%v/%v", blockID, kind, pkh, field))
 	if err != nil {
-		log.Println("[Balance] Unable to query endpoint")
+		logger.WithField("endpoint", "balance").WithError(err).Error("Unable to query endpoint")
 		return nil, err
 	}
 
 	defer resp.Body.Close()
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		log.Println("[Balance] Unable to read response")
+		logger.WithField("endpoint", "balance").WithError(err).Error("Unable to read response")
 		return nil, err
 	}
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, errNotFound
+	}
 
 	// Parse Body
 	balanceString := strings.Trim(string(body), "\"\n")
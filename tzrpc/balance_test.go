@@ -0,0 +1,33 @@
+package tzrpc
+
+import "testing"
+
+func TestGetBalanceAt(t *testing.T) {
+	defer serveNode(map[string]string{
+		"chains/main/blocks/BLbalance/context/delegates/tz1baker/full_balance": `"12000000000"`,
+		"chains/main/blocks/BLbalance/context/contracts/tz1baker/balance":      `"2000000000"`,
+		"chains/main/blocks/BLbalance/context/contracts/tz1account/balance":    `"3000000"`,
+	})()
+
+	// Bakers' full balances, under whichever name their protocol uses
+	balance, err := GetBalanceAt("tz1baker", "BLbalance")
+	check(err)
+	if balance.Int64() != 12000 {
+		t.Error("Expected the baker's full balance, found", balance)
+	}
+	balance, err = GetSpendableBalanceAt("tz1baker", "BLbalance")
+	check(err)
+	if balance.Int64() != 2000 {
+		t.Error("Expected the baker's spendable balance, found", balance)
+	}
+
+	// Other addresses only have a spendable balance
+	balance, err = GetBalanceAt("tz1account", "BLbalance")
+	check(err)
+	if balance.Int64() != 3 {
+		t.Error("Expected the account's balance, found", balance)
+	}
+	if _, err := GetBalanceAt("bad", "BLbalance"); err == nil {
+		t.Error("Expected an error for an invalid address")
+	}
+}
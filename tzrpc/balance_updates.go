@@ -0,0 +1,106 @@
+package tzrpc
+
+import (
+	"strconv"
+)
+
+// BalanceUpdate from block or operation metadata.  `Change` is in mutez
+type BalanceUpdate struct {
+	Kind     string
+	Category string
+	Contract string
+	// Delegate of a frozen balance, or of the staker it's frozen for
+	Delegate string
+	// Cycle is -1 for updates that aren't for a cycle, as on Tenderbake
+	Cycle  int64
+	Change int64
+	// Origin is "block" for updates in the block metadata, otherwise the kind
+	// of the operation that caused them
+	Origin string
+	// Source is the category of the minted or accumulated balance that funded
+	// a credit, like "baking rewards" or "block fees", on Tenderbake
+	Source string
+}
+
+// Owner of the balance, whether a frozen balance or a contract
+func (u BalanceUpdate) Owner() string {
+	if len(u.Delegate) > 0 {
+		return u.Delegate
+	}
+	return u.Contract
+}
+
+// BalanceUpdates in the block metadata, every operation's metadata and every
+// operation result
+func (block *Block) BalanceUpdates() []BalanceUpdate {
+	updates := []BalanceUpdate{}
+
+	if metadata, ok := block.data["metadata"].(map[string]interface{}); ok {
+		updates = appendBalanceUpdates(updates, metadata, "block")
+	}
+
+	operations := block.data["operations"].([]interface{})
+	for _, outer := range operations {
+		for _, inner := range outer.([]interface{}) {
+			operation := inner.(map[string]interface{})
+			contents := operation["contents"].([]interface{})
+			for _, c := range contents {
+				typedContents := c.(map[string]interface{})
+				kind, _ := typedContents["kind"].(string)
+				metadata, ok := typedContents["metadata"].(map[string]interface{})
+				if !ok {
+					continue
+				}
+				updates = appendBalanceUpdates(updates, metadata, kind)
+				if result, ok := metadata["operation_result"].(map[string]interface{}); ok {
+					updates = appendBalanceUpdates(updates, result, kind)
+				}
+			}
+		}
+	}
+	return updates
+}
+
+// appendBalanceUpdates found in the `balance_updates` of `data`
+func appendBalanceUpdates(updates []BalanceUpdate, data map[string]interface{}, origin string) []BalanceUpdate {
+	balanceUpdates, ok := data["balance_updates"].([]interface{})
+	if !ok {
+		return updates
+	}
+
+	// Tenderbake mints rewards, and accumulates fees, in a debit followed by
+	// the credits it funds
+	source, remaining := "", int64(0)
+	for _, update := range balanceUpdates {
+		typedUpdate, ok := update.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if nodeOrigin, _ := typedUpdate["origin"].(string); nodeOrigin == "simulation" {
+			continue
+		}
+		u := BalanceUpdate{Origin: origin, Cycle: -1}
+		u.Kind, _ = typedUpdate["kind"].(string)
+		u.Category, _ = typedUpdate["category"].(string)
+		u.Contract, _ = typedUpdate["contract"].(string)
+		u.Delegate = updateDelegate(typedUpdate)
+		if cycle, ok := typedUpdate["cycle"].(float64); ok {
+			u.Cycle = int64(cycle)
+		}
+		if change, ok := typedUpdate["change"].(string); ok {
+			u.Change, _ = strconv.ParseInt(change, 10, 64)
+		}
+
+		switch {
+		case (u.Kind == "minted" || u.Kind == "accumulator") && u.Change < 0:
+			source, remaining = u.Category, -u.Change
+		case remaining > 0 && u.Change > 0:
+			u.Source = source
+			remaining -= u.Change
+		default:
+			source, remaining = "", 0
+		}
+		updates = append(updates, u)
+	}
+	return updates
+}
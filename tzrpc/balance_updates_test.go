@@ -0,0 +1,155 @@
+package tzrpc
+
+import (
+	"testing"
+)
+
+func TestBalanceUpdates(t *testing.T) {
+	block := getBlock("../tests/double_endorsement.json")
+
+	updates := block.BalanceUpdates()
+	if len(updates) != 67 {
+		t.Error("Expected 67 balance updates but found", len(updates))
+	}
+
+	// Baking deposit and reward in the block metadata come first
+	deposit := updates[1]
+	if deposit.Origin != "block" || deposit.Category != "deposits" || deposit.Change != 512000000 || deposit.Cycle != 135 {
+		t.Error("Incorrect baking deposit", deposit)
+	}
+
+	fees := int64(0)
+	for _, u := range updates {
+		if u.Category == "fees" && u.Change > 0 && u.Delegate == "tz1gk3TDbU7cJuiBRMhwQXVvgDnjsxuWhcEA" {
+			fees += u.Change
+		}
+	}
+	if fees != 5020 {
+		t.Error("Expected 5020 mutez of fees but found", fees)
+	}
+}
+
+func TestTenderbakeBalanceUpdates(t *testing.T) {
+	block, err := parseBlock([]byte(`{
+		"header": {"level": 5000},
+		"metadata": {"balance_updates": [
+			{"kind": "accumulator", "category": "block fees", "change": "-2450", "origin": "block"},
+			{"kind": "contract", "contract": "tz1baker", "change": "2450", "origin": "block"},
+			{"kind": "minted", "category": "baking rewards", "change": "-5000000", "origin": "block"},
+			{"kind": "freezer", "category": "deposits", "staker": {"baker_own_stake": "tz1baker"}, "change": "1000000", "origin": "block"},
+			{"kind": "freezer", "category": "deposits", "staker": {"baker_edge": "tz1baker"}, "change": "500000", "origin": "block"},
+			{"kind": "contract", "contract": "tz1baker", "change": "3500000", "origin": "block"},
+			{"kind": "contract", "contract": "tz1baker", "change": "-8000000000", "origin": "block"},
+			{"kind": "freezer", "category": "deposits", "staker": {"contract": "tz1staker", "delegate": "tz1baker"}, "change": "8000000000", "origin": "block"},
+			{"kind": "minted", "category": "baking rewards", "change": "-1", "origin": "simulation"}
+		]},
+		"operations": []
+	}`))
+	check(err)
+
+	updates := block.BalanceUpdates()
+	if len(updates) != 8 {
+		t.Fatal("Expected 8 balance updates but found", len(updates))
+	}
+	sources := []string{"", "block fees", "", "baking rewards", "baking rewards", "baking rewards", "", ""}
+	for i, u := range updates {
+		if u.Source != sources[i] {
+			t.Errorf("Expected source %q of update %v but found %q", sources[i], i, u.Source)
+		}
+		if u.Cycle != -1 {
+			t.Errorf("Expected no cycle for update %v but found %v", i, u.Cycle)
+		}
+	}
+	for _, i := range []int{1, 3, 4, 5, 6, 7} {
+		if updates[i].Owner() != "tz1baker" {
+			t.Errorf("Expected update %v for tz1baker but found %v", i, updates[i].Owner())
+		}
+	}
+}
+
+func TestParseConstants(t *testing.T) {
+	constants, err := parseConstants([]byte(`{
+		"preserved_cycles": 5,
+		"blocks_per_cycle": 4096,
+		"endorsers_per_block": 32,
+		"block_security_deposit": "512000000",
+		"baking_reward_per_endorsement": ["1250000", "187500"],
+		"endorsement_reward": ["1250000", "833333"]
+	}`))
+	check(err)
+
+	if constants.PreservedCycles != 5 || constants.BlockSecurityDeposit != 512000000 {
+		t.Error("Incorrect constants", constants)
+	}
+	if constants.MaxBakingReward() != 40000000 {
+		t.Error("Expected max baking reward of 40000000 but found", constants.MaxBakingReward())
+	}
+	if constants.MaxEndorsementReward() != 1250000 {
+		t.Error("Expected max endorsement reward of 1250000 but found", constants.MaxEndorsementReward())
+	}
+}
+
+func TestParseTenderbakeRewardConstants(t *testing.T) {
+	constants, err := parseConstants([]byte(`{
+		"preserved_cycles": 5,
+		"blocks_per_cycle": 8192,
+		"consensus_committee_size": 7000,
+		"consensus_threshold": 4667,
+		"frozen_deposits_percentage": 10,
+		"baking_reward_fixed_portion": "10000000",
+		"baking_reward_bonus_per_slot": "4286",
+		"endorsing_reward_per_slot": "2857"
+	}`))
+	check(err)
+
+	if constants.MaxBakingReward() != 10000000+4286*2333 {
+		t.Error("Expected max baking reward of", 10000000+4286*2333, "but found", constants.MaxBakingReward())
+	}
+	if constants.MaxEndorsementReward() != 2857 {
+		t.Error("Expected max endorsement reward of 2857 but found", constants.MaxEndorsementReward())
+	}
+}
+
+func TestGetRewardConstants(t *testing.T) {
+	defer serveNode(map[string]string{
+		"chains/main/blocks/BLissuance/context/constants": `{
+			"blocks_per_cycle": 30720,
+			"consensus_committee_size": 7000,
+			"consensus_threshold": 4667,
+			"limit_of_delegation_over_baking": 9
+		}`,
+		"chains/main/blocks/BLissuance/context/issuance/expected_issuance": `[
+			{"cycle": 800, "baking_reward_fixed_portion": "5000000", "baking_reward_bonus_per_slot": "2000", "attesting_reward_per_slot": "3000"},
+			{"cycle": 801, "baking_reward_fixed_portion": "4000000", "baking_reward_bonus_per_slot": "1500", "attesting_reward_per_slot": "2500"}
+		]`,
+	})()
+
+	constants, err := GetRewardConstants("BLissuance", 801)
+	check(err)
+	if constants.MaxBakingReward() != 4000000+1500*2333 {
+		t.Error("Expected max baking reward of", 4000000+1500*2333, "but found", constants.MaxBakingReward())
+	}
+	if constants.MaxEndorsementReward() != 2500 {
+		t.Error("Expected max endorsement reward of 2500 but found", constants.MaxEndorsementReward())
+	}
+
+	// Cycles the node hasn't forecast have no expected rewards, so aren't compared
+	constants, err = GetRewardConstants("BLissuance", 700)
+	check(err)
+	if constants.MaxBakingReward() != 0 || constants.MaxEndorsementReward() != 0 {
+		t.Error("Expected no rewards without issuance but found", constants.MaxBakingReward(), constants.MaxEndorsementReward())
+	}
+}
+
+func TestParseParisConstants(t *testing.T) {
+	constants, err := parseConstants([]byte(`{
+		"consensus_rights_delay": 2,
+		"blocks_per_cycle": 24576,
+		"limit_of_delegation_over_baking": 9
+	}`))
+	check(err)
+
+	if constants.PreservedCycles != 2 {
+		t.Error("Expected rights 2 cycles ahead but found", constants.PreservedCycles)
+	}
+}
@@ -4,10 +4,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
-	"log"
 	"math/big"
 	"net/http"
-	"os"
 	"strconv"
 )
 
@@ -16,23 +14,32 @@ type Block struct {
 	data map[string]interface{}
 }
 
-// GetBlock data
+// GetBlock `offset` levels below the block with `blockHash`
 func GetBlock(blockHash string, offset int64) (*Block, error) {
+	if block, ok := cachedBlock(blockHash, offset); ok {
+		return block, nil
+	}
+
 	// Get Payload
-	resp, err := http.Get(fmt.Sprintf("%v/chains/main/blocks/%v~%v", os.Getenv("NODE_URL"), blockHash, offset))
+	resp, err := get(http.DefaultClient, "block", fmt.Sprintf("chains/main/blocks/%v~%v", blockHash, offset))
 	if err != nil {
-		log.Println("[Block Endorsements] Unable to query endpoint")
+		logger.WithField("endpoint", "block").WithError(err).Error("Unable to query endpoint")
 		return nil, err
 	}
 
 	defer resp.Body.Close()
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		log.Println("[Block Endorsements] Unable to read response")
+		logger.WithField("endpoint", "block").WithError(err).Error("Unable to read response")
 		return nil, err
 	}
 
-	return parseBlock(body)
+	block, err := parseBlock(body)
+	if err != nil {
+		return nil, err
+	}
+	cacheBlock(blockHash, offset, block)
+	return block, nil
 }
 
 func parseBlock(body []byte) (*Block, error) {
@@ -41,7 +48,7 @@ func parseBlock(body []byte) (*Block, error) {
 	err := json.Unmarshal(body, &block.data)
 
 	if err != nil {
-		log.Println("[Block Endorsements] Unable to parse json payload", err)
+		logger.WithField("endpoint", "block").WithError(err).Error("Unable to parse json payload")
 		return nil, err
 	}
 
@@ -59,20 +66,100 @@ func (block *Block) Hash() string {
 	return block.data["hash"].(string)
 }
 
-// Baker of the current block
+// Predecessor hash of the current block
+func (block *Block) Predecessor() string {
+	header := block.data["header"].(map[string]interface{})
+	return header["predecessor"].(string)
+}
+
+// Baker of the current block, resolved from its consensus key if need be
 func (block *Block) Baker() string {
 	metadata := block.data["metadata"].(map[string]interface{})
-	return metadata["baker"].(string)
+	return ResolveDelegate(metadata["baker"].(string))
 }
 
-// BakerPriority that baked this block
+// BakerPriority that baked this block.  Tenderbake protocols bake in rounds
+// rather than priorities, so this is the block's round there
 func (block *Block) BakerPriority() int64 {
 	header := block.data["header"].(map[string]interface{})
-	return int64(header["priority"].(float64))
+	if priority, ok := header["priority"].(float64); ok {
+		return int64(priority)
+	}
+	return block.Round()
+}
+
+// Round the block was baked at, from the last element of its Tenderbake
+// fitness, or 0 on earlier protocols
+func (block *Block) Round() int64 {
+	return fitnessRound(block.data["header"].(map[string]interface{}))
+}
+
+// fitnessRound of a block header, or 0 before Tenderbake
+func fitnessRound(header map[string]interface{}) int64 {
+	fitness, ok := header["fitness"].([]interface{})
+	if !ok || len(fitness) < 5 {
+		return 0
+	}
+	round, _ := fitness[len(fitness)-1].(string)
+	value, err := strconv.ParseInt(round, 16, 64)
+	if err != nil {
+		return 0
+	}
+	return value
+}
+
+// PayloadRound the block's operations were first proposed at.  It's lower
+// than Round when a locked payload was reproposed
+func (block *Block) PayloadRound() int64 {
+	header := block.data["header"].(map[string]interface{})
+	if round, ok := header["payload_round"].(float64); ok {
+		return int64(round)
+	}
+	return block.Round()
 }
 
-// Endorsements for the current block
+// Endorsements for the current block.  Protocols before Edo include plain
+// `endorsement` operations, later ones wrap them in `endorsement_with_slot`.
+// Tenderbake protocols only carry the delegate's first slot and its power,
+// renamed to `attestation` since Oxford
 func (block *Block) Endorsements(delegate string) ([]int64, error) {
+	return block.consensusSlots(delegate, "endorsement", "endorsement_with_slot", "attestation", "attestation_with_dal"), nil
+}
+
+// Preattestations for the current block's round, as slots.  Only Tenderbake
+// blocks include them, named `preendorsement` before Oxford
+func (block *Block) Preattestations(delegate string) []int64 {
+	return block.consensusSlots(delegate, "preendorsement", "preattestation")
+}
+
+// EndorsedLevels the delegate's endorsements in this block were for, normally
+// only the predecessor's
+func (block *Block) EndorsedLevels(delegate string) []int64 {
+	levels := []int64{}
+	for _, c := range block.contentsOfKind("endorsement", "endorsement_with_slot", "attestation", "attestation_with_dal") {
+		metadata, ok := c["metadata"].(map[string]interface{})
+		if !ok || !matchesDelegate(metadata, delegate) {
+			continue
+		}
+		e, _ := consensusOperation(c)
+		if e.Level > 0 && !containsLevel(levels, e.Level) {
+			levels = append(levels, e.Level)
+		}
+	}
+	return levels
+}
+
+func containsLevel(levels []int64, level int64) bool {
+	for _, l := range levels {
+		if l == level {
+			return true
+		}
+	}
+	return false
+}
+
+// consensusSlots of `delegate` in operations of any of `kinds`
+func (block *Block) consensusSlots(delegate string, kinds ...string) []int64 {
 	operations := block.data["operations"].([]interface{})
 
 	var slots []int64
@@ -82,20 +169,59 @@ func (block *Block) Endorsements(delegate string) ([]int64, error) {
 			contents := operation["contents"].([]interface{})
 			for _, c := range contents {
 				typedContents := c.(map[string]interface{})
-				if typedContents["kind"] != "endorsement_with_slot" {
+				kind, _ := typedContents["kind"].(string)
+				if !containsKind(kinds, kind) {
 					continue
 				}
 				metadata := typedContents["metadata"].(map[string]interface{})
-				if metadata["delegate"] != delegate {
+				if !matchesDelegate(metadata, delegate) {
 					continue
 				}
-				for _, slotID := range metadata["slots"].([]interface{}) {
-					slots = append(slots, int64(slotID.(float64)))
+				if list, ok := metadata["slots"].([]interface{}); ok {
+					for _, slotID := range list {
+						slots = append(slots, int64(slotID.(float64)))
+					}
+					continue
 				}
+				slots = append(slots, powerSlots(toInt64(typedContents["slot"]), consensusPower(metadata))...)
 			}
 		}
 	}
-	return slots, nil
+	return slots
+}
+
+// consensusPower of a Tenderbake consensus operation or right, whichever
+// name its protocol gives it
+func consensusPower(data map[string]interface{}) int64 {
+	for _, key := range []string{"consensus_power", "attestation_power", "endorsement_power", "preendorsement_power", "preattestation_power", "endorsing_power"} {
+		switch power := data[key].(type) {
+		case float64, string:
+			return toInt64(power)
+		case map[string]interface{}:
+			return toInt64(power["slots"])
+		}
+	}
+	return 0
+}
+
+// powerSlots stands in for the `power` slots of a Tenderbake delegate, who is
+// only identified by its `first` slot.  The others aren't consecutive on
+// chain, so only the first slot and the count are meaningful
+func powerSlots(first int64, power int64) []int64 {
+	slots := make([]int64, 0, power)
+	for i := int64(0); i < power; i++ {
+		slots = append(slots, first+i)
+	}
+	return slots
+}
+
+func containsKind(kinds []string, kind string) bool {
+	for _, k := range kinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
 }
 
 // Transaction data
@@ -118,15 +244,7 @@ func (block *Block) Transactions() []Transaction {
 			for _, c := range contents {
 				typedContents := c.(map[string]interface{})
 				if typedContents["kind"] == "transaction" {
-					amount, _ := strconv.Atoi(typedContents["amount"].(string))
-					fee, _ := strconv.Atoi(typedContents["fee"].(string))
-
-					tx = append(tx, Transaction{
-						Source:      typedContents["source"].(string),
-						Destination: typedContents["destination"].(string),
-						Amount:      amount,
-						Fee:         fee,
-					})
+					tx = append(tx, transactionFrom(typedContents))
 				}
 			}
 		}
@@ -138,6 +256,7 @@ func (block *Block) Transactions() []Transaction {
 type DoubleBaking struct {
 	SlashedBaker  string
 	RewardedBaker string
+	// SlashedAmount in mutez, as a positive number
 	SlashedAmount int
 	Level         int
 }
@@ -155,23 +274,31 @@ func (block *Block) DoubleBakings() []DoubleBaking {
 				typedContents := c.(map[string]interface{})
 				if typedContents["kind"] == "double_baking_evidence" {
 					typedMetadata := typedContents["metadata"].(map[string]interface{})
-					balanceUpdates := typedMetadata["balance_updates"].([]interface{})
+					balanceUpdates, _ := typedMetadata["balance_updates"].([]interface{})
 
 					double := DoubleBaking{}
+					if header, ok := typedContents["bh1"].(map[string]interface{}); ok {
+						double.Level = int(toInt64(header["level"]))
+					}
+					// Since Paris the slashing only happens at the end of the cycle
+					double.SlashedBaker, _ = typedMetadata["punished_delegate"].(string)
+					double.RewardedBaker, _ = typedMetadata["rewarded_delegate"].(string)
 
 					for _, update := range balanceUpdates {
 						typedUpdate := update.(map[string]interface{})
 						if typedUpdate["category"] == "deposits" {
-							double.SlashedBaker = typedUpdate["delegate"].(string)
+							double.SlashedBaker = updateDelegate(typedUpdate)
 							amount, _ := strconv.Atoi(typedUpdate["change"].(string))
-							double.SlashedAmount = amount
-							// Broken in: https://tzstats.com/block/511005
-							// double.Level = int(typedUpdate["level"].(float64))
+							double.SlashedAmount = -amount
 						} else if typedUpdate["category"] == "rewards" {
 							double.RewardedBaker = typedUpdate["delegate"].(string)
+						} else if typedUpdate["kind"] == "contract" && len(double.RewardedBaker) == 0 {
+							// Tenderbake pays the accuser directly
+							double.RewardedBaker, _ = typedUpdate["contract"].(string)
 						}
 					}
 
+					double.SlashedBaker = ResolveDelegate(double.SlashedBaker)
 					doubles = append(doubles, double)
 				}
 			}
@@ -180,12 +307,26 @@ func (block *Block) DoubleBakings() []DoubleBaking {
 	return doubles
 }
 
-// DoubleEndorsement data
+// DoubleEndorsement data, including Tenderbake's double (pre)attestations
 type DoubleEndorsement struct {
+	// Kind of the evidence, e.g. `double_endorsement_evidence` or
+	// `double_preattestation_evidence`
+	Kind            string
 	SlashedEndorser string
 	RewardedBaker   string
-	SlashedAmount   int
-	Cycle           int
+	// SlashedAmount in mutez, as a positive number
+	SlashedAmount int
+	Cycle         int
+	// Level of the double signed operations, when the evidence includes it
+	Level int
+}
+
+// Evidence of double signing a consensus operation, across protocols
+var doubleEndorsementKinds = []string{
+	"double_endorsement_evidence",
+	"double_preendorsement_evidence",
+	"double_attestation_evidence",
+	"double_preattestation_evidence",
 }
 
 // DoubleEndorsements in the block
@@ -199,11 +340,21 @@ func (block *Block) DoubleEndorsements() []DoubleEndorsement {
 			contents := operation["contents"].([]interface{})
 			for _, c := range contents {
 				typedContents := c.(map[string]interface{})
-				if typedContents["kind"] == "double_endorsement_evidence" {
+				kind, _ := typedContents["kind"].(string)
+				if containsKind(doubleEndorsementKinds, kind) {
 					typedMetadata := typedContents["metadata"].(map[string]interface{})
-					balanceUpdates := typedMetadata["balance_updates"].([]interface{})
+					balanceUpdates, _ := typedMetadata["balance_updates"].([]interface{})
 
-					double := DoubleEndorsement{}
+					double := DoubleEndorsement{Kind: kind}
+					if op, ok := typedContents["op1"].(map[string]interface{}); ok {
+						if signed, ok := op["operations"].(map[string]interface{}); ok && signed["level"] != nil {
+							double.Level = int(toInt64(signed["level"]))
+							double.Cycle = int(Cycle(int64(double.Level)))
+						}
+					}
+					// Since Paris the slashing only happens at the end of the cycle
+					double.SlashedEndorser, _ = typedMetadata["punished_delegate"].(string)
+					double.RewardedBaker, _ = typedMetadata["rewarded_delegate"].(string)
 
 					for _, update := range balanceUpdates {
 						typedUpdate := update.(map[string]interface{})
@@ -211,16 +362,23 @@ func (block *Block) DoubleEndorsements() []DoubleEndorsement {
 						case "deposits", "fees", "rewards":
 							amount, _ := strconv.Atoi(typedUpdate["change"].(string))
 							if amount < 0 {
-								double.SlashedEndorser = typedUpdate["delegate"].(string)
-								double.Cycle = int(typedUpdate["cycle"].(float64))
-								amount, _ := strconv.Atoi(typedUpdate["change"].(string))
+								double.SlashedEndorser = updateDelegate(typedUpdate)
+								if cycle, ok := typedUpdate["cycle"].(float64); ok {
+									double.Cycle = int(cycle)
+								}
 								double.SlashedAmount -= amount
 							} else if amount > 0 {
-								double.RewardedBaker = typedUpdate["delegate"].(string)
+								double.RewardedBaker = updateDelegate(typedUpdate)
+							}
+						default:
+							if typedUpdate["kind"] == "contract" && len(double.RewardedBaker) == 0 {
+								// Tenderbake pays the accuser directly
+								double.RewardedBaker, _ = typedUpdate["contract"].(string)
 							}
 						}
 					}
 
+					double.SlashedEndorser = ResolveDelegate(double.SlashedEndorser)
 					doubles = append(doubles, double)
 				}
 			}
@@ -229,6 +387,22 @@ func (block *Block) DoubleEndorsements() []DoubleEndorsement {
 	return doubles
 }
 
+// updateDelegate of a frozen balance update, which Oxford moved into a
+// `staker`
+func updateDelegate(update map[string]interface{}) string {
+	if delegate, ok := update["delegate"].(string); ok {
+		return delegate
+	}
+	if staker, ok := update["staker"].(map[string]interface{}); ok {
+		for _, key := range []string{"baker", "delegate", "baker_own_stake", "baker_edge"} {
+			if delegate, ok := staker[key].(string); ok {
+				return delegate
+			}
+		}
+	}
+	return ""
+}
+
 // Delegation to a baker
 type Delegation struct {
 	Source   string
@@ -39,7 +39,7 @@ func TestDoubleBaking(t *testing.T) {
 		log.Println("Failure: Incorrect rewarded baker.  Received", doubles[0].RewardedBaker)
 		t.Fail()
 	}
-	if doubles[0].SlashedAmount != -64000000 {
+	if doubles[0].SlashedAmount != 64000000 {
 		log.Println("Failure: Incorrect slashed amount.  Received", doubles[0].SlashedAmount)
 		t.Fail()
 	}
@@ -63,7 +63,7 @@ func TestDoubleBaking2(t *testing.T) {
 		log.Println("Failure: Incorrect rewarded baker.  Received", doubles[0].RewardedBaker)
 		t.Fail()
 	}
-	if doubles[0].SlashedAmount != -960000000 {
+	if doubles[0].SlashedAmount != 960000000 {
 		log.Println("Failure: Incorrect slashed amount.  Received", doubles[0].SlashedAmount)
 		t.Fail()
 	}
@@ -2,11 +2,8 @@ package tzrpc
 
 import (
 	"encoding/json"
-	"fmt"
 	"io/ioutil"
-	"log"
 	"net/http"
-	"os"
 	"time"
 )
 
@@ -28,16 +25,16 @@ func GetBootstrapped() (*Bootstrapped, error) {
 		Timeout: time.Duration(15 * time.Second),
 	}
 
-	resp, err := client.Get(fmt.Sprintf("%v/monitor/bootstrapped", os.Getenv("NODE_URL")))
+	resp, err := get(&client, "bootstrapped", "monitor/bootstrapped")
 	if err != nil {
-		log.Println("[Bootstrapped] Unable to query endpoint: ", err)
+		logger.WithField("endpoint", "bootstrapped").WithError(err).Error("Unable to query endpoint")
 		return nil, err
 	}
 
 	defer resp.Body.Close()
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		log.Println("[Bootstrapped] Unable to read response: ", err)
+		logger.WithField("endpoint", "bootstrapped").WithError(err).Error("Unable to read response")
 		return nil, err
 	}
 
@@ -45,12 +42,12 @@ func GetBootstrapped() (*Bootstrapped, error) {
 	var bootstrapped map[string]string
 	err = json.Unmarshal(body, &bootstrapped)
 	if err != nil {
-		log.Println("[Bootstrapped] Unable to parse json payload: ", err)
+		logger.WithField("endpoint", "bootstrapped").WithError(err).Error("Unable to parse json payload")
 		return nil, err
 	}
 	timestamp, err := time.Parse(time.RFC3339, bootstrapped["timestamp"])
 	if err != nil {
-		log.Println("[Bootstrapped] Unable to parse timestamp: ", err)
+		logger.WithField("endpoint", "bootstrapped").WithError(err).Error("Unable to parse timestamp")
 		return nil, err
 	}
 	secondsBehind := time.Now().Sub(timestamp).Seconds()
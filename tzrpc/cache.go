@@ -0,0 +1,162 @@
+package tzrpc
+
+import (
+	"container/list"
+	"fmt"
+	"sync"
+	"time"
+
+	"gitlab.com/polychainlabs/tezos-network-monitor/metrics"
+)
+
+// Blocks this deep below a head are considered final, so their level will
+// always resolve to the same hash
+const finalityDepth = 60
+
+// Recent blocks may still be reorganized, so are only found by level briefly
+const recentBlockTTL = 30 * time.Second
+
+// Cache sizes, in entries
+const blockCacheSize = 512
+const rightsCacheSize = 8192
+
+// Blocks by hash and by `hash~offset` request, which never change
+var blockCache = newLRU("block", blockCacheSize)
+
+// Hashes of blocks by level, expiring unless final
+var levelCache = newLRU("level", rightsCacheSize)
+
+// Rights by level, which never change once computed.  Failed queries are
+// cached briefly too, so repeated lookups don't each hit the node
+var bakingRightsCache = newLRU("baking_rights", rightsCacheSize)
+var endorsingRightsCache = newLRU("endorsing_rights", rightsCacheSize)
+
+const rightsRetry = time.Minute
+
+// Highest level of any block fetched, so rights are only queried in the
+// context of blocks that exist
+var highestLevel int64
+var highestLevelLock sync.RWMutex
+
+// lru cache of values, evicting the least recently used entry when full
+type lru struct {
+	lock  sync.Mutex
+	name  string
+	size  int
+	items map[interface{}]*list.Element
+	order *list.List
+}
+
+type lruEntry struct {
+	key     interface{}
+	value   interface{}
+	expires time.Time
+}
+
+func newLRU(name string, size int) *lru {
+	return &lru{
+		name:  name,
+		size:  size,
+		items: map[interface{}]*list.Element{},
+		order: list.New(),
+	}
+}
+
+// get a value from the cache, recording a hit or miss
+func (c *lru) get(key interface{}) (interface{}, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	element, ok := c.items[key]
+	if ok {
+		entry := element.Value.(*lruEntry)
+		if entry.expires.IsZero() || time.Now().Before(entry.expires) {
+			c.order.MoveToFront(element)
+			metrics.CacheRequests.WithLabelValues(c.name, "hit").Inc()
+			return entry.value, true
+		}
+		c.order.Remove(element)
+		delete(c.items, key)
+	}
+	metrics.CacheRequests.WithLabelValues(c.name, "miss").Inc()
+	return nil, false
+}
+
+// add a value to the cache.  Values with a zero `ttl` never expire, and are
+// only evicted when the cache is full
+func (c *lru) add(key interface{}, value interface{}, ttl time.Duration) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	entry := &lruEntry{key: key, value: value}
+	if ttl > 0 {
+		entry.expires = time.Now().Add(ttl)
+	}
+	if element, ok := c.items[key]; ok {
+		element.Value = entry
+		c.order.MoveToFront(element)
+		return
+	}
+	c.items[key] = c.order.PushFront(entry)
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*lruEntry).key)
+	}
+}
+
+type blockRequest struct {
+	hash   string
+	offset int64
+}
+
+// cachedBlock `offset` levels below the block with `hash`, if we've seen it
+// before
+func cachedBlock(hash string, offset int64) (*Block, bool) {
+	if block, ok := blockCache.get(blockRequest{hash, offset}); ok {
+		return block.(*Block), true
+	}
+
+	// Blocks can also be found by level, if we know the head's level
+	head, ok := blockCache.get(blockRequest{hash, 0})
+	if !ok {
+		return nil, false
+	}
+	levelHash, ok := levelCache.get(head.(*Block).Level() - offset)
+	if !ok {
+		return nil, false
+	}
+	block, ok := blockCache.get(blockRequest{levelHash.(string), 0})
+	if !ok {
+		return nil, false
+	}
+	return block.(*Block), true
+}
+
+// cacheBlock fetched `offset` levels below the block with `hash`
+func cacheBlock(hash string, offset int64, block *Block) {
+	highestLevelLock.Lock()
+	if block.Level() > highestLevel {
+		highestLevel = block.Level()
+	}
+	highestLevelLock.Unlock()
+
+	blockCache.add(blockRequest{hash, offset}, block, 0)
+	blockCache.add(blockRequest{block.Hash(), 0}, block, 0)
+	if offset >= finalityDepth {
+		levelCache.add(block.Level(), block.Hash(), 0)
+	} else {
+		levelCache.add(block.Level(), block.Hash(), recentBlockTTL)
+	}
+}
+
+// rightsContext to query rights at `level` in: the level's own block for past
+// levels, so archived cycles can be queried too, otherwise the head
+func rightsContext(level int64) string {
+	highestLevelLock.RLock()
+	defer highestLevelLock.RUnlock()
+	if level <= highestLevel {
+		return fmt.Sprint(level)
+	}
+	return "head"
+}
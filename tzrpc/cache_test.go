@@ -0,0 +1,46 @@
+package tzrpc
+
+import (
+	"testing"
+)
+
+func TestLRUEviction(t *testing.T) {
+	cache := newLRU("test", 2)
+	cache.add("a", 1, 0)
+	cache.add("b", 2, 0)
+	cache.get("a")
+	cache.add("c", 3, 0)
+
+	if _, ok := cache.get("b"); ok {
+		t.Error("Expected least recently used entry to be evicted")
+	}
+	if v, ok := cache.get("a"); !ok || v.(int) != 1 {
+		t.Error("Expected recently used entry to remain")
+	}
+}
+
+func TestCachedBlockByLevel(t *testing.T) {
+	head := getBlock("../tests/double_endorsement.json")
+	final := getBlock("../tests/double_baking_2.json")
+	offset := head.Level() - final.Level()
+
+	cacheBlock(head.Hash(), 0, head)
+	cacheBlock(head.Hash(), offset, final)
+
+	// Same request
+	block, ok := cachedBlock(head.Hash(), offset)
+	if !ok || block.Hash() != final.Hash() {
+		t.Error("Expected cached block for the same request")
+	}
+
+	// Final block requested relative to a later head
+	later := blockRequest{"BLaterHead", 0}
+	blockCache.add(later, &Block{data: map[string]interface{}{
+		"hash":   "BLaterHead",
+		"header": map[string]interface{}{"level": float64(head.Level() + 10)},
+	}}, 0)
+	block, ok = cachedBlock("BLaterHead", offset+10)
+	if !ok || block.Hash() != final.Hash() {
+		t.Error("Expected final block to be found by level")
+	}
+}
@@ -0,0 +1,204 @@
+package tzrpc
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sync"
+)
+
+// ConsensusKeys a delegate signs blocks and consensus operations with, by
+// public key hash.  Protocols before Lima only sign with the manager key
+type ConsensusKeys struct {
+	Active  string
+	Pending []PendingConsensusKey
+}
+
+// PendingConsensusKey that becomes active at the start of `Cycle`
+type PendingConsensusKey struct {
+	Cycle int64
+	PKH   string
+}
+
+// Keys active or pending, active first
+func (k *ConsensusKeys) Keys() []string {
+	keys := []string{}
+	if len(k.Active) > 0 {
+		keys = append(keys, k.Active)
+	}
+	for _, p := range k.Pending {
+		keys = append(keys, p.PKH)
+	}
+	return keys
+}
+
+// Consensus keys registered for our delegates, mapped to the delegate
+var consensusKeyOwners = map[string]string{}
+var consensusKeyLock sync.RWMutex
+
+// RegisterConsensusKeys of `delegate`, so rights, operations and evidence
+// signed with any of them are attributed to it
+func RegisterConsensusKeys(delegate string, keys []string) {
+	consensusKeyLock.Lock()
+	defer consensusKeyLock.Unlock()
+	for key, owner := range consensusKeyOwners {
+		if owner == delegate {
+			delete(consensusKeyOwners, key)
+		}
+	}
+	for _, key := range keys {
+		if key != delegate {
+			consensusKeyOwners[key] = delegate
+		}
+	}
+}
+
+// ResolveDelegate a public key hash belongs to, which is itself unless it's
+// a registered consensus key
+func ResolveDelegate(pkh string) string {
+	consensusKeyLock.RLock()
+	defer consensusKeyLock.RUnlock()
+	if owner, ok := consensusKeyOwners[pkh]; ok {
+		return owner
+	}
+	return pkh
+}
+
+// matchesDelegate if `data` names `delegate` directly or by one of its
+// registered consensus keys
+func matchesDelegate(data map[string]interface{}, delegate string) bool {
+	for _, key := range []string{"delegate", "consensus_key", "consensus_pkh"} {
+		if pkh, ok := data[key].(string); ok && ResolveDelegate(pkh) == delegate {
+			return true
+		}
+	}
+	return false
+}
+
+// GetConsensusKeys of `pkh` at the block with `blockID`, a hash, level or
+// `head`. Schema defined here:
+// https://tezos.gitlab.io/alphanet/api/rpc.html#get-block-id-context-delegates-pkh-consensus-key
+func GetConsensusKeys(pkh string, blockID string) (*ConsensusKeys, error) {
+	resp, err := get(http.DefaultClient, "consensus_key", fmt.Sprintf("chains/main/blocks/%v/context/delegates/%v/consensus_key", blockID, pkh))
+	if err != nil {
+		logger.WithField("endpoint", "consensus_key").WithError(err).Error("Unable to query endpoint")
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		logger.WithField("endpoint", "consensus_key").WithError(err).Error("Unable to read response")
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		// Before Lima the manager key is the consensus key
+		return &ConsensusKeys{Active: pkh}, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		err := fmt.Errorf("Unexpected status %v from consensus_key: %v", resp.StatusCode, string(body))
+		logger.WithField("endpoint", "consensus_key").WithError(err).Error("Unable to query endpoint")
+		return nil, err
+	}
+
+	keys, err := parseConsensusKeys(body)
+	if err != nil {
+		logger.WithField("endpoint", "consensus_key").WithError(err).Error("Unable to parse json payload")
+		return nil, err
+	}
+	return keys, nil
+}
+
+func parseConsensusKeys(body []byte) (*ConsensusKeys, error) {
+	var data map[string]interface{}
+	if err := json.Unmarshal(body, &data); err != nil {
+		return nil, err
+	}
+	keys := ConsensusKeys{}
+	if active, ok := data["active"].(map[string]interface{}); ok {
+		keys.Active, _ = active["pkh"].(string)
+	}
+	if pendings, ok := data["pendings"].([]interface{}); ok {
+		for _, p := range pendings {
+			typedPending := p.(map[string]interface{})
+			pending := PendingConsensusKey{Cycle: toInt64(typedPending["cycle"])}
+			pending.PKH, _ = typedPending["pkh"].(string)
+			keys.Pending = append(keys.Pending, pending)
+		}
+	}
+	return &keys, nil
+}
+
+// ConsensusKeyUpdate operation, setting the key a delegate will sign with
+type ConsensusKeyUpdate struct {
+	Source    string
+	PublicKey string
+	Status    string
+}
+
+// Drain of a delegate's spendable balance, signed with its consensus key
+type Drain struct {
+	Delegate     string
+	ConsensusKey string
+	Destination  string
+	// Amount moved to the destination, in mutez
+	Amount int64
+}
+
+// ConsensusKeyUpdates in the block
+func (block *Block) ConsensusKeyUpdates() []ConsensusKeyUpdate {
+	updates := []ConsensusKeyUpdate{}
+	for _, c := range block.contentsOfKind("update_consensus_key") {
+		u := ConsensusKeyUpdate{}
+		u.Source, _ = c["source"].(string)
+		u.PublicKey, _ = c["pk"].(string)
+		if metadata, ok := c["metadata"].(map[string]interface{}); ok {
+			if result, ok := metadata["operation_result"].(map[string]interface{}); ok {
+				u.Status, _ = result["status"].(string)
+			}
+		}
+		updates = append(updates, u)
+	}
+	return updates
+}
+
+// Drains in the block
+func (block *Block) Drains() []Drain {
+	drains := []Drain{}
+	for _, c := range block.contentsOfKind("drain_delegate") {
+		d := Drain{}
+		d.Delegate, _ = c["delegate"].(string)
+		d.ConsensusKey, _ = c["consensus_key"].(string)
+		d.Destination, _ = c["destination"].(string)
+		if metadata, ok := c["metadata"].(map[string]interface{}); ok {
+			for _, u := range appendBalanceUpdates(nil, metadata, "drain_delegate") {
+				if u.Kind == "contract" && u.Contract == d.Destination && u.Change > 0 {
+					d.Amount += u.Change
+				}
+			}
+		}
+		drains = append(drains, d)
+	}
+	return drains
+}
+
+// contentsOfKind across every operation in the block
+func (block *Block) contentsOfKind(kinds ...string) []map[string]interface{} {
+	found := []map[string]interface{}{}
+	operations := block.data["operations"].([]interface{})
+	for _, outer := range operations {
+		for _, inner := range outer.([]interface{}) {
+			operation := inner.(map[string]interface{})
+			contents := operation["contents"].([]interface{})
+			for _, c := range contents {
+				typedContents := c.(map[string]interface{})
+				kind, _ := typedContents["kind"].(string)
+				if containsKind(kinds, kind) {
+					found = append(found, typedContents)
+				}
+			}
+		}
+	}
+	return found
+}
@@ -0,0 +1,75 @@
+package tzrpc
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseConsensusKeys(t *testing.T) {
+	keys, err := parseConsensusKeys([]byte(`{
+		"active": {"pkh": "tz1active", "pk": "edpkactive"},
+		"pendings": [{"cycle": 700, "pkh": "tz1pending", "pk": "edpkpending"}]
+	}`))
+	check(err)
+	if keys.Active != "tz1active" || len(keys.Pending) != 1 || keys.Pending[0].Cycle != 700 || keys.Pending[0].PKH != "tz1pending" {
+		t.Errorf("Incorrect consensus keys %+v", keys)
+	}
+	if k := keys.Keys(); len(k) != 2 || k[0] != "tz1active" {
+		t.Error("Incorrect keys", k)
+	}
+}
+
+func TestConsensusKeyMatching(t *testing.T) {
+	baker := "tz1ConsensusKeyBaker1111111111111"
+	block := getBlock("../tests/consensus_keys_block.json")
+
+	// Unknown keys aren't attributed
+	if slots, _ := block.Endorsements(baker); len(slots) != 0 {
+		t.Error("Expected no slots before registering the key, found", slots)
+	}
+
+	RegisterConsensusKeys(baker, []string{"tz1ConsensusKeyOfOurBaker11111111", baker})
+	if slots, _ := block.Endorsements(baker); len(slots) != 2 || slots[0] != 9 {
+		t.Error("Expected 2 slots from slot 9, found", slots)
+	}
+	if block.Baker() != baker {
+		t.Error("Expected the baker to be resolved, found", block.Baker())
+	}
+
+	var endorsing []map[string]interface{}
+	check(json.Unmarshal([]byte(`[
+		{"level": 100, "delegates": [
+			{"delegate": "tz1Other", "consensus_key": "tz1ConsensusKeyOfOurBaker11111111", "first_slot": 1, "attestation_power": 3}
+		]}
+	]`), &endorsing))
+	er := EndorsingRights{data: endorsing}
+	if slots := er.Slots(baker); len(slots) != 3 {
+		t.Error("Expected rights through the consensus key, found", slots)
+	}
+
+	// Re-registering drops keys no longer in use
+	RegisterConsensusKeys(baker, []string{baker})
+	if ResolveDelegate("tz1ConsensusKeyOfOurBaker11111111") == baker {
+		t.Error("Expected the old key to be dropped")
+	}
+}
+
+func TestConsensusKeyOperations(t *testing.T) {
+	block := getBlock("../tests/consensus_keys_block.json")
+
+	updates := block.ConsensusKeyUpdates()
+	if len(updates) != 1 || updates[0].Source != "tz1ConsensusKeyBaker1111111111111" || updates[0].Status != "applied" ||
+		updates[0].PublicKey != "edpkConsensusKeyNewPublicKey111111111111111111111111" {
+		t.Errorf("Incorrect consensus key updates %+v", updates)
+	}
+
+	drains := block.Drains()
+	if len(drains) != 1 {
+		t.Fatal("Expected 1 drain, found", len(drains))
+	}
+	d := drains[0]
+	if d.Delegate != "tz1ConsensusKeyBaker1111111111111" || d.ConsensusKey != "tz1ConsensusKeyOfOurBaker11111111" ||
+		d.Destination != "tz1ConsensusKeyThief1111111111111" || d.Amount != 990000000 {
+		t.Errorf("Incorrect drain %+v", d)
+	}
+}
@@ -0,0 +1,244 @@
+package tzrpc
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Constants only change with the protocol, so are refreshed hourly
+const constantsTTL = time.Hour
+
+var constantsCache = newLRU("constants", 64)
+
+// Constants of the protocol, with amounts in mutez
+type Constants struct {
+	// PreservedCycles ahead that rights are known for.  Paris renamed it
+	// `consensus_rights_delay`
+	PreservedCycles     int64
+	BlocksPerCycle      int64
+	BlocksPerCommitment int64
+	// NonceRevelationThreshold levels into the following cycle that a seed
+	// nonce can be revealed in, since Kathmandu
+	NonceRevelationThreshold   int64
+	EndorsersPerBlock          int64
+	TokensPerRoll              int64
+	BlockSecurityDeposit       int64
+	EndorsementSecurityDeposit int64
+	// BakingRewardPerEndorsement by priority of the baked block.  Protocols
+	// before Babylon paid a flat BlockReward instead
+	BakingRewardPerEndorsement []int64
+	BlockReward                int64
+	// EndorsementReward per slot by priority of the including block
+	EndorsementReward []int64
+	// FrozenDepositsPercentage of its staking balance a Tenderbake baker must
+	// keep frozen.  Oxford replaced it with LimitOfDelegationOverBaking
+	FrozenDepositsPercentage    int64
+	LimitOfDelegationOverBaking int64
+	// BakingRewardFixedPortion of a Tenderbake block, plus a
+	// BakingRewardBonusPerSlot for each endorsement slot included over the
+	// ConsensusThreshold.  Paris replaced these with the expected issuance
+	BakingRewardFixedPortion int64
+	BakingRewardBonusPerSlot int64
+	EndorsingRewardPerSlot   int64
+	ConsensusThreshold       int64
+}
+
+// Tenderbake protocols freeze a share of the staking balance instead of a
+// deposit per right
+func (c *Constants) Tenderbake() bool {
+	return c.BlockSecurityDeposit == 0 && c.EndorsementSecurityDeposit == 0 &&
+		(c.FrozenDepositsPercentage > 0 || c.LimitOfDelegationOverBaking > 0)
+}
+
+// RequiredDeposit a Tenderbake baker must keep frozen to use all of
+// `stakingBalance`
+func (c *Constants) RequiredDeposit(stakingBalance int64) int64 {
+	if c.FrozenDepositsPercentage > 0 {
+		return stakingBalance * c.FrozenDepositsPercentage / 100
+	}
+	if c.LimitOfDelegationOverBaking > 0 {
+		return stakingBalance / (c.LimitOfDelegationOverBaking + 1)
+	}
+	return 0
+}
+
+// CapacityRatio of staking balance to its own balance a baker's deposits can
+// cover.  Tenderbake fixes it.  Earlier protocols froze a deposit for every
+// right over `preserved_cycles`, so it depends on the whole `networkStake`
+func (c *Constants) CapacityRatio(networkStake int64) float64 {
+	if c.FrozenDepositsPercentage > 0 {
+		return 100 / float64(c.FrozenDepositsPercentage)
+	}
+	if c.LimitOfDelegationOverBaking > 0 {
+		return float64(c.LimitOfDelegationOverBaking + 1)
+	}
+	perCycle := c.BlocksPerCycle * (c.BlockSecurityDeposit + c.EndorsersPerBlock*c.EndorsementSecurityDeposit)
+	deposits := (c.PreservedCycles + 1) * perCycle
+	if deposits <= 0 {
+		return 0
+	}
+	return float64(networkStake) / float64(deposits)
+}
+
+// StakedCapacity when only a baker's staked, frozen balance counts towards its
+// capacity, since Oxford.  Earlier its whole balance could be frozen
+func (c *Constants) StakedCapacity() bool {
+	return c.FrozenDepositsPercentage == 0 && c.LimitOfDelegationOverBaking > 0
+}
+
+// NonceRevelationEnd is the last level a seed nonce committed in `cycle` can
+// be revealed at.  Before Kathmandu it could be revealed during all of the
+// following cycle
+func (c *Constants) NonceRevelationEnd(cycle int64) int64 {
+	first, last := CycleLevels(cycle + 1)
+	if c.NonceRevelationThreshold > 0 {
+		return first + c.NonceRevelationThreshold - 1
+	}
+	return last
+}
+
+// MaxBakingReward for a fully endorsed block baked at priority 0
+func (c *Constants) MaxBakingReward() int64 {
+	if c.BakingRewardFixedPortion > 0 {
+		return c.BakingRewardFixedPortion + c.BakingRewardBonusPerSlot*(c.EndorsersPerBlock-c.ConsensusThreshold)
+	}
+	if len(c.BakingRewardPerEndorsement) > 0 {
+		return c.BakingRewardPerEndorsement[0] * c.EndorsersPerBlock
+	}
+	return c.BlockReward
+}
+
+// MaxEndorsementReward per slot, endorsed into a block of priority 0
+func (c *Constants) MaxEndorsementReward() int64 {
+	if c.EndorsingRewardPerSlot > 0 {
+		return c.EndorsingRewardPerSlot
+	}
+	if len(c.EndorsementReward) > 0 {
+		return c.EndorsementReward[0]
+	}
+	return 0
+}
+
+// GetConstants of the protocol at the block with `blockID`, a hash, level or
+// `head`. Schema defined here:
+// https://tezos.gitlab.io/alphanet/api/rpc.html#get-block-id-context-constants
+func GetConstants(blockID string) (*Constants, error) {
+	if constants, ok := constantsCache.get(blockID); ok {
+		return constants.(*Constants), nil
+	}
+
+	// Get Payload
+	resp, err := get(http.DefaultClient, "constants", fmt.Sprintf("chains/main/blocks/%v/context/constants", blockID))
+	if err != nil {
+		logger.WithField("endpoint", "constants").WithError(err).Error("Unable to query endpoint")
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		logger.WithField("endpoint", "constants").WithError(err).Error("Unable to read response")
+		return nil, err
+	}
+
+	constants, err := parseConstants(body)
+	if err != nil {
+		logger.WithField("endpoint", "constants").WithError(err).Error("Unable to parse json payload")
+		return nil, err
+	}
+	constantsCache.add(blockID, constants, constantsTTL)
+	return constants, nil
+}
+
+// GetRewardConstants of the protocol at the block with `blockID`, with the
+// rewards of `cycle`.  Since Paris, rewards follow adaptive issuance so come
+// from the node's expected issuance instead of the constants
+func GetRewardConstants(blockID string, cycle int64) (*Constants, error) {
+	constants, err := GetConstants(blockID)
+	if err != nil || !constants.Tenderbake() || constants.BakingRewardFixedPortion > 0 {
+		return constants, err
+	}
+
+	var issuance []map[string]interface{}
+	err = getRights("issuance", fmt.Sprintf("chains/main/blocks/%v/context/issuance/expected_issuance", blockID), &issuance)
+	if err != nil {
+		return nil, err
+	}
+	for _, i := range issuance {
+		if toInt64(i["cycle"]) != cycle {
+			continue
+		}
+		rewards := *constants
+		rewards.BakingRewardFixedPortion = toInt64(i["baking_reward_fixed_portion"])
+		rewards.BakingRewardBonusPerSlot = toInt64(i["baking_reward_bonus_per_slot"])
+		rewards.EndorsingRewardPerSlot = toInt64(i["attesting_reward_per_slot"])
+		return &rewards, nil
+	}
+	return constants, nil
+}
+
+func parseConstants(body []byte) (*Constants, error) {
+	var data map[string]interface{}
+	if err := json.Unmarshal(body, &data); err != nil {
+		return nil, err
+	}
+	return &Constants{
+		PreservedCycles:            toInt64(firstOf(data, "preserved_cycles", "consensus_rights_delay")),
+		BlocksPerCycle:             toInt64(data["blocks_per_cycle"]),
+		BlocksPerCommitment:        toInt64(data["blocks_per_commitment"]),
+		NonceRevelationThreshold:   toInt64(data["nonce_revelation_threshold"]),
+		EndorsersPerBlock:          endorsersPerBlock(data),
+		TokensPerRoll:              toInt64(data["tokens_per_roll"]),
+		BlockSecurityDeposit:       toInt64(data["block_security_deposit"]),
+		EndorsementSecurityDeposit: toInt64(data["endorsement_security_deposit"]),
+		BakingRewardPerEndorsement: int64List(data["baking_reward_per_endorsement"]),
+		BlockReward:                toInt64(data["block_reward"]),
+		EndorsementReward:          int64List(data["endorsement_reward"]),
+
+		FrozenDepositsPercentage:    toInt64(data["frozen_deposits_percentage"]),
+		LimitOfDelegationOverBaking: toInt64(data["limit_of_delegation_over_baking"]),
+		BakingRewardFixedPortion:    toInt64(data["baking_reward_fixed_portion"]),
+		BakingRewardBonusPerSlot:    toInt64(data["baking_reward_bonus_per_slot"]),
+		EndorsingRewardPerSlot:      toInt64(firstOf(data, "endorsing_reward_per_slot", "attesting_reward_per_slot")),
+		ConsensusThreshold:          toInt64(firstOf(data, "consensus_threshold", "consensus_threshold_size")),
+	}, nil
+}
+
+// endorsersPerBlock, which Tenderbake calls its consensus committee size
+func endorsersPerBlock(data map[string]interface{}) int64 {
+	if size, ok := data["consensus_committee_size"]; ok {
+		return toInt64(size)
+	}
+	return toInt64(data["endorsers_per_block"])
+}
+
+// toInt64 from a json number or numeric string, or 0
+func toInt64(value interface{}) int64 {
+	switch v := value.(type) {
+	case float64:
+		return int64(v)
+	case string:
+		i, _ := strconv.ParseInt(v, 10, 64)
+		return i
+	}
+	return 0
+}
+
+// int64List from a json list of numbers or a single number
+func int64List(value interface{}) []int64 {
+	switch v := value.(type) {
+	case []interface{}:
+		amounts := []int64{}
+		for _, amount := range v {
+			amounts = append(amounts, toInt64(amount))
+		}
+		return amounts
+	case nil:
+		return nil
+	}
+	return []int64{toInt64(value)}
+}
@@ -0,0 +1,349 @@
+package tzrpc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+// How long to wait before retrying a cycle whose rights couldn't be fetched
+const cycleRightsRetry = 5 * time.Minute
+
+// Prefetched rights for one delegate over a whole cycle, by level
+var bakingCycleCache = newLRU("baking_cycle_rights", 64)
+var endorsingCycleCache = newLRU("endorsing_cycle_rights", 64)
+
+type cycleKey struct {
+	cycle    int64
+	delegate string
+}
+
+// CycleBakingRights of one delegate, indexed by level
+type CycleBakingRights struct {
+	Cycle    int64
+	Delegate string
+	levels   cycleRange
+	priority map[int64]int64
+}
+
+// CycleEndorsingRights of one delegate, indexed by level
+type CycleEndorsingRights struct {
+	Cycle    int64
+	Delegate string
+	levels   cycleRange
+	slots    map[int64][]int64
+}
+
+// cycleRange of levels the prefetched rights are known to cover
+type cycleRange struct {
+	first int64
+	last  int64
+	// mismatched when the node returned rights outside [first, last], so our
+	// cycle eras are out of date
+	mismatched bool
+}
+
+func newCycleRange(cycle int64) cycleRange {
+	first, last := CycleLevels(cycle)
+	return cycleRange{first: first, last: last}
+}
+
+// add a level the node returned rights for
+func (r *cycleRange) add(level int64) {
+	if level < r.first || level > r.last {
+		r.mismatched = true
+	}
+}
+
+// covers `level`, so a level without rights means the delegate has none there
+func (r cycleRange) covers(level int64) bool {
+	return !r.mismatched && level >= r.first && level <= r.last
+}
+
+// GetCycleBakingRights of `delegate` for the whole `cycle` in one request, in
+// the context of the cycle's first block if it exists so archived cycles can
+// be queried too
+// Schema defined here: https://tezos.gitlab.io/alphanet/api/rpc.html#get-block-id-helpers-baking-rights
+func GetCycleBakingRights(cycle int64, delegate string) (*CycleBakingRights, error) {
+	firstLevel, _ := CycleLevels(cycle)
+	return GetCycleBakingRightsAt(cycle, delegate, rightsContext(firstLevel))
+}
+
+// GetCycleBakingRightsAt the block with `blockID`, a hash, level or `head`.
+// Rights for future cycles can only be queried from the head
+func GetCycleBakingRightsAt(cycle int64, delegate string, blockID string) (*CycleBakingRights, error) {
+	var data []map[string]interface{}
+	err := getRights("baking_rights",
+		fmt.Sprintf("chains/main/blocks/%v/helpers/baking_rights?cycle=%v&delegate=%v", blockID, cycle, delegate), &data)
+	if err != nil {
+		return nil, err
+	}
+
+	rights := CycleBakingRights{Cycle: cycle, Delegate: delegate, levels: newCycleRange(cycle), priority: map[int64]int64{}}
+	for _, r := range data {
+		rawLevel, ok := r["level"]
+		if !ok || !matchesDelegate(r, delegate) {
+			continue
+		}
+		level := toInt64(rawLevel)
+		rights.levels.add(level)
+		priority := rightsRound(r)
+		if existing, ok := rights.priority[level]; !ok || priority < existing {
+			rights.priority[level] = priority
+		}
+	}
+	return &rights, nil
+}
+
+// GetCycleEndorsingRights of `delegate` for the whole `cycle` in one request,
+// in the context of the cycle's first block if it exists so archived cycles
+// can be queried too
+// Schema defined here: https://tezos.gitlab.io/alphanet/api/rpc.html#get-block-id-helpers-endorsing-rights
+func GetCycleEndorsingRights(cycle int64, delegate string) (*CycleEndorsingRights, error) {
+	firstLevel, _ := CycleLevels(cycle)
+	return GetCycleEndorsingRightsAt(cycle, delegate, rightsContext(firstLevel))
+}
+
+// GetCycleEndorsingRightsAt the block with `blockID`, a hash, level or `head`.
+// Rights for future cycles can only be queried from the head
+func GetCycleEndorsingRightsAt(cycle int64, delegate string, blockID string) (*CycleEndorsingRights, error) {
+	var data []map[string]interface{}
+	err := getEndorsingRights(blockID, fmt.Sprintf("cycle=%v&delegate=%v", cycle, delegate), &data)
+	if err != nil {
+		return nil, err
+	}
+
+	rights := CycleEndorsingRights{Cycle: cycle, Delegate: delegate, levels: newCycleRange(cycle), slots: map[int64][]int64{}}
+	for _, r := range data {
+		rawLevel, ok := r["level"]
+		if !ok {
+			continue
+		}
+		level := toInt64(rawLevel)
+		rights.levels.add(level)
+		rights.slots[level] = append(rights.slots[level], rightsSlots(r, delegate)...)
+	}
+	return &rights, nil
+}
+
+// Priority of the delegate at `level`, or -1 if it has no rights
+func (r *CycleBakingRights) Priority(level int64) int64 {
+	if priority, ok := r.priority[level]; ok {
+		return priority
+	}
+	return -1
+}
+
+// Slots of the delegate at `level`
+func (r *CycleEndorsingRights) Slots(level int64) []int64 {
+	return r.slots[level]
+}
+
+// Blocks the delegate has rights to bake at `priority` during the cycle
+func (r *CycleBakingRights) Blocks(priority int64) int64 {
+	count := int64(0)
+	for _, p := range r.priority {
+		if p == priority {
+			count++
+		}
+	}
+	return count
+}
+
+// BlocksAfter `level` the delegate has rights to bake at `priority`, for what's
+// left of the cycle
+func (r *CycleBakingRights) BlocksAfter(level int64, priority int64) int64 {
+	count := int64(0)
+	for l, p := range r.priority {
+		if l > level && p == priority {
+			count++
+		}
+	}
+	return count
+}
+
+// SlotCount of the delegate's endorsement slots during the cycle
+func (r *CycleEndorsingRights) SlotCount() int64 {
+	count := int64(0)
+	for _, slots := range r.slots {
+		count += int64(len(slots))
+	}
+	return count
+}
+
+// SlotCountAfter `level` of the delegate's endorsement slots, for what's left
+// of the cycle
+func (r *CycleEndorsingRights) SlotCountAfter(level int64) int64 {
+	count := int64(0)
+	for l, slots := range r.slots {
+		if l > level {
+			count += int64(len(slots))
+		}
+	}
+	return count
+}
+
+// FutureBakingRights of `delegate` for an upcoming `cycle`, queried from the
+// head once and cached like prefetched rights
+func FutureBakingRights(cycle int64, delegate string) (*CycleBakingRights, error) {
+	key := cycleKey{cycle, delegate}
+	if cached, ok := bakingCycleCache.get(key); ok {
+		if rights, ok := cached.(*CycleBakingRights); ok && rights != nil {
+			return rights, nil
+		}
+	}
+	rights, err := GetCycleBakingRightsAt(cycle, delegate, "head")
+	if err != nil {
+		return nil, err
+	}
+	bakingCycleCache.add(key, rights, 0)
+	return rights, nil
+}
+
+// FutureEndorsingRights of `delegate` for an upcoming `cycle`, queried from
+// the head once and cached like prefetched rights
+func FutureEndorsingRights(cycle int64, delegate string) (*CycleEndorsingRights, error) {
+	key := cycleKey{cycle, delegate}
+	if cached, ok := endorsingCycleCache.get(key); ok {
+		if rights, ok := cached.(*CycleEndorsingRights); ok && rights != nil {
+			return rights, nil
+		}
+	}
+	rights, err := GetCycleEndorsingRightsAt(cycle, delegate, "head")
+	if err != nil {
+		return nil, err
+	}
+	endorsingCycleCache.add(key, rights, 0)
+	return rights, nil
+}
+
+// BakingPriority of `delegate` at `level`, or -1 if it has no rights. Uses the
+// delegate's prefetched cycle rights, falling back to the level's rights when
+// they couldn't be fetched or don't cover `level`
+func BakingPriority(level int64, delegate string) (int64, error) {
+	key := cycleKey{Cycle(level), delegate}
+	cached, ok := bakingCycleCache.get(key)
+	if !ok {
+		rights, err := GetCycleBakingRights(key.cycle, delegate)
+		if err != nil {
+			logger.WithError(err).WithField("cycle", key.cycle).Warn("Unable to prefetch baking rights.  Querying per level")
+			bakingCycleCache.add(key, nil, cycleRightsRetry)
+		} else {
+			bakingCycleCache.add(key, rights, 0)
+		}
+		cached = rights
+	}
+	if rights, ok := cached.(*CycleBakingRights); ok && rights != nil && rights.levels.covers(level) {
+		return rights.Priority(level), nil
+	}
+
+	bakingRights, err := GetBakingRights(level)
+	if err != nil {
+		return -1, err
+	}
+	return bakingRights.GetBakingPriority(delegate), nil
+}
+
+// EndorsingSlots of `delegate` at `level`. Uses the delegate's prefetched
+// cycle rights, falling back to the level's rights when they couldn't be
+// fetched or don't cover `level`
+func EndorsingSlots(level int64, delegate string) ([]int64, error) {
+	key := cycleKey{Cycle(level), delegate}
+	cached, ok := endorsingCycleCache.get(key)
+	if !ok {
+		rights, err := GetCycleEndorsingRights(key.cycle, delegate)
+		if err != nil {
+			logger.WithError(err).WithField("cycle", key.cycle).Warn("Unable to prefetch endorsing rights.  Querying per level")
+			endorsingCycleCache.add(key, nil, cycleRightsRetry)
+		} else {
+			endorsingCycleCache.add(key, rights, 0)
+		}
+		cached = rights
+	}
+	if rights, ok := cached.(*CycleEndorsingRights); ok && rights != nil && rights.levels.covers(level) {
+		return rights.Slots(level), nil
+	}
+
+	endorsingRights, err := GetEndorsingRights(level)
+	if err != nil {
+		return nil, err
+	}
+	return endorsingRights.Slots(delegate), nil
+}
+
+// rightsRound of a baking right, its priority before Tenderbake
+func rightsRound(r map[string]interface{}) int64 {
+	if priority, ok := r["priority"].(float64); ok {
+		return int64(priority)
+	}
+	return toInt64(r["round"])
+}
+
+// rightsSlots of `delegate` in one level's endorsing rights.  Tenderbake
+// protocols list each delegate's first slot and power instead of its slots
+func rightsSlots(r map[string]interface{}, delegate string) []int64 {
+	if delegates, ok := r["delegates"].([]interface{}); ok {
+		for _, d := range delegates {
+			typed, ok := d.(map[string]interface{})
+			if ok && matchesDelegate(typed, delegate) {
+				return powerSlots(toInt64(typed["first_slot"]), consensusPower(typed))
+			}
+		}
+		return nil
+	}
+	if !matchesDelegate(r, delegate) {
+		return nil
+	}
+	var slots []int64
+	raw, _ := r["slots"].([]interface{})
+	for _, slot := range raw {
+		slots = append(slots, toInt64(slot))
+	}
+	return slots
+}
+
+// getEndorsingRights in the context of the block with `blockID`, filtered by
+// `query`.  Oxford renamed the helper to `attestation_rights`
+func getEndorsingRights(blockID string, query string, data interface{}) error {
+	err := getRights("endorsing_rights", fmt.Sprintf("chains/main/blocks/%v/helpers/endorsing_rights?%v", blockID, query), data)
+	if err == errNotFound {
+		return getRights("attestation_rights", fmt.Sprintf("chains/main/blocks/%v/helpers/attestation_rights?%v", blockID, query), data)
+	}
+	return err
+}
+
+// errNotFound when the node doesn't serve a path, e.g. an RPC its protocol
+// renamed
+var errNotFound = errors.New("Not found")
+
+// getRights from the `endpoint` rights helper at `path` into `data`
+func getRights(endpoint string, path string, data interface{}) error {
+	resp, err := get(http.DefaultClient, endpoint, path)
+	if err != nil {
+		logger.WithField("endpoint", endpoint).WithError(err).Error("Unable to query endpoint")
+		return err
+	}
+
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		logger.WithField("endpoint", endpoint).WithError(err).Error("Unable to read response")
+		return err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return errNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Unexpected status %v from %v: %v", resp.StatusCode, endpoint, string(body))
+	}
+
+	err = json.Unmarshal(body, data)
+	if err != nil {
+		logger.WithField("endpoint", endpoint).WithError(err).Error("Unable to parse json payload")
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,253 @@
+package tzrpc
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+// serveNode answers requests for the paths in `routes`, with their query, and
+// 404s the rest.  Returns a function restoring the previous node
+func serveNode(routes map[string]string) func() {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := routes[strings.TrimPrefix(r.URL.RequestURI(), "/")]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(body))
+	}))
+	previous := os.Getenv("NODE_URL")
+	os.Setenv("NODE_URL", server.URL)
+	return func() {
+		server.Close()
+		os.Setenv("NODE_URL", previous)
+	}
+}
+
+// setHighestLevel of the blocks we've seen, as though fetched
+func setHighestLevel(level int64) {
+	highestLevelLock.Lock()
+	defer highestLevelLock.Unlock()
+	highestLevel = level
+}
+
+func TestCycleEras(t *testing.T) {
+	defer SetCycleEras(cycleEras)
+
+	if Cycle(4096) != 0 || Cycle(4097) != 1 {
+		t.Error("Incorrect cycles for 4096 block cycles", Cycle(4096), Cycle(4097))
+	}
+
+	// Granada doubled the cycle length from cycle 388
+	SetCycleEras([]CycleEra{
+		{FirstLevel: 1, FirstCycle: 0, BlocksPerCycle: 4096},
+		{FirstLevel: 1589249, FirstCycle: 388, BlocksPerCycle: 8192},
+	})
+	if c := Cycle(1589248); c != 387 {
+		t.Error("Expected the last level before Granada in cycle 387, found", c)
+	}
+	if c := Cycle(1597441); c != 389 {
+		t.Error("Expected cycle 389, found", c)
+	}
+	if first, last := CycleLevels(387); first != 1585153 || last != 1589248 {
+		t.Error("Incorrect levels of cycle 387", first, last)
+	}
+	if first, last := CycleLevels(389); first != 1597441 || last != 1605632 {
+		t.Error("Incorrect levels of cycle 389", first, last)
+	}
+
+	// Blocks report their own cycle
+	block := Block{data: map[string]interface{}{
+		"header":   map[string]interface{}{"level": float64(1597441)},
+		"metadata": map[string]interface{}{"level_info": map[string]interface{}{"cycle": float64(1000)}},
+	}}
+	if block.Cycle() != 1000 {
+		t.Error("Expected the block's cycle from its metadata, found", block.Cycle())
+	}
+}
+
+func TestLoadCycleEras(t *testing.T) {
+	defer SetCycleEras(cycleEras)
+	defer serveNode(map[string]string{
+		"chains/main/blocks/head/context/raw/json/cycle_eras": `[
+			{"first_level": 3268609, "first_cycle": 593, "blocks_per_cycle": 16384, "blocks_per_commitment": 128},
+			{"first_level": 1, "first_cycle": 0, "blocks_per_cycle": 4096, "blocks_per_commitment": 32}
+		]`,
+	})()
+
+	check(LoadCycleEras())
+	if c := Cycle(3268609 + 16384); c != 594 {
+		t.Error("Expected cycle 594 from the node's eras, found", c)
+	}
+}
+
+func TestGetCycleBakingRights(t *testing.T) {
+	setHighestLevel(500000)
+	defer serveNode(map[string]string{
+		"chains/main/blocks/409601/helpers/baking_rights?cycle=100&delegate=tz1cycle": `[
+			{"level": 409601, "delegate": "tz1cycle", "priority": 2},
+			{"level": 409601, "delegate": "tz1cycle", "priority": 0},
+			{"delegate": "tz1cycle", "priority": 0},
+			{"level": 409700, "delegate": "tz1cycle", "round": 1}
+		]`,
+	})()
+
+	rights, err := GetCycleBakingRights(100, "tz1cycle")
+	check(err)
+	if p := rights.Priority(409601); p != 0 {
+		t.Error("Expected the best priority of a level, found", p)
+	}
+	if p := rights.Priority(409700); p != 1 {
+		t.Error("Expected the round as priority, found", p)
+	}
+	if p := rights.Priority(409602); p != -1 {
+		t.Error("Expected no rights, found", p)
+	}
+	if rights.Blocks(0) != 1 || rights.Blocks(1) != 1 {
+		t.Error("Incorrect block counts", rights.Blocks(0), rights.Blocks(1))
+	}
+	if rights.BlocksAfter(409601, 0) != 0 || rights.BlocksAfter(409601, 1) != 1 {
+		t.Error("Incorrect block counts for the rest of the cycle", rights.BlocksAfter(409601, 0), rights.BlocksAfter(409601, 1))
+	}
+}
+
+func TestGetCycleEndorsingRights(t *testing.T) {
+	setHighestLevel(500000)
+	defer serveNode(map[string]string{
+		"chains/main/blocks/413697/helpers/endorsing_rights?cycle=101&delegate=tz1cycle": `[
+			{"level": 413697, "delegate": "tz1cycle", "slots": [3, 7]},
+			{"delegate": "tz1cycle", "slots": [1]},
+			{"level": 413698, "delegates": [{"delegate": "tz1cycle", "first_slot": 10, "attestation_power": 2}]}
+		]`,
+	})()
+
+	rights, err := GetCycleEndorsingRights(101, "tz1cycle")
+	check(err)
+	if slots := rights.Slots(413697); len(slots) != 2 || slots[0] != 3 || slots[1] != 7 {
+		t.Error("Incorrect slots", slots)
+	}
+	if slots := rights.Slots(413698); len(slots) != 2 || slots[0] != 10 {
+		t.Error("Incorrect Tenderbake slots", slots)
+	}
+	if rights.SlotCount() != 4 {
+		t.Error("Expected 4 slots, found", rights.SlotCount())
+	}
+	if rights.SlotCountAfter(413697) != 2 {
+		t.Error("Expected 2 slots for the rest of the cycle, found", rights.SlotCountAfter(413697))
+	}
+}
+
+func TestBakingPriority(t *testing.T) {
+	setHighestLevel(500000)
+	defer serveNode(map[string]string{
+		"chains/main/blocks/417793/helpers/baking_rights?cycle=102&delegate=tz1prefetched": `[
+			{"level": 417800, "delegate": "tz1prefetched", "priority": 1}
+		]`,
+		// The node's cycle 103 doesn't match our eras
+		"chains/main/blocks/421889/helpers/baking_rights?cycle=103&delegate=tz1stale": `[
+			{"level": 430000, "delegate": "tz1stale", "priority": 0}
+		]`,
+		"chains/main/blocks/421900/helpers/baking_rights?level=421900": `[
+			{"level": 421900, "delegate": "tz1stale", "priority": 3}
+		]`,
+	})()
+
+	// Prefetched rights answer every level of their cycle
+	priority, err := BakingPriority(417800, "tz1prefetched")
+	check(err)
+	if priority != 1 {
+		t.Error("Expected the prefetched priority, found", priority)
+	}
+	priority, err = BakingPriority(417801, "tz1prefetched")
+	check(err)
+	if priority != -1 {
+		t.Error("Expected no rights, found", priority)
+	}
+
+	// Levels the prefetched rights don't cover are queried directly
+	priority, err = BakingPriority(421900, "tz1stale")
+	check(err)
+	if priority != 3 {
+		t.Error("Expected the level's own priority, found", priority)
+	}
+}
+
+func TestEndorsingSlots(t *testing.T) {
+	setHighestLevel(500000)
+	defer serveNode(map[string]string{
+		"chains/main/blocks/425985/helpers/endorsing_rights?cycle=104&delegate=tz1prefetched": `[
+			{"level": 425990, "delegate": "tz1prefetched", "slots": [5]}
+		]`,
+		// Cycle 105 can't be prefetched
+		"chains/main/blocks/430090/helpers/endorsing_rights?level=430090": `[
+			{"level": 430090, "delegate": "tz1unfetched", "slots": [8, 9]}
+		]`,
+	})()
+
+	slots, err := EndorsingSlots(425990, "tz1prefetched")
+	check(err)
+	if len(slots) != 1 || slots[0] != 5 {
+		t.Error("Expected the prefetched slots, found", slots)
+	}
+
+	slots, err = EndorsingSlots(430090, "tz1unfetched")
+	check(err)
+	if len(slots) != 2 || slots[0] != 8 {
+		t.Error("Expected the level's own slots, found", slots)
+	}
+}
+
+func TestLoadCycleErasFromHead(t *testing.T) {
+	defer SetCycleEras(cycleEras)
+	defer serveNode(map[string]string{
+		"monitor/bootstrapped": `{"block": "BLhead", "timestamp": "2025-01-01T00:00:00Z"}`,
+		"chains/main/blocks/BLhead~0": `{"hash": "BLhead", "header": {"level": 8000010},
+			"metadata": {"level_info": {"level": 8000010, "cycle": 900, "cycle_position": 9}}}`,
+		"chains/main/blocks/BLhead/context/constants": `{"blocks_per_cycle": 10800, "preserved_cycles": 2}`,
+	})()
+
+	// Without the raw context, the current era comes from the head
+	check(LoadCycleEras())
+	if first, last := CycleLevels(900); first != 8000001 || last != 8010800 {
+		t.Error("Incorrect levels of the head's cycle", first, last)
+	}
+	if c := Cycle(8010801); c != 901 {
+		t.Error("Expected cycle 901, found", c)
+	}
+	if c := Cycle(3268609); c != 593 {
+		t.Error("Expected earlier eras to be kept, found cycle", c)
+	}
+}
+
+func TestRightsContext(t *testing.T) {
+	setHighestLevel(600000)
+	routes := map[string]string{
+		"chains/main/blocks/600000/helpers/baking_rights?level=600000": `[{"level": 600000, "delegate": "tz1past", "priority": 0}]`,
+		"chains/main/blocks/head/helpers/baking_rights?level=600001":   `[{"level": 600001, "delegate": "tz1next", "priority": 1}]`,
+	}
+	defer serveNode(routes)()
+
+	// Past levels in their own block's context, later ones in the head's
+	rights, err := GetBakingRights(600000)
+	check(err)
+	if rights.GetBakingPriority("tz1past") != 0 {
+		t.Error("Expected rights from the level's own block")
+	}
+	rights, err = GetBakingRights(600001)
+	check(err)
+	if rights.GetBakingPriority("tz1next") != 1 {
+		t.Error("Expected rights from the head")
+	}
+
+	// Failures are remembered rather than retried right away
+	if _, err := GetEndorsingRights(600000); err == nil {
+		t.Fatal("Expected an error for unserved rights")
+	}
+	routes["chains/main/blocks/600000/helpers/endorsing_rights?level=600000"] = `[]`
+	if _, err := GetEndorsingRights(600000); err == nil {
+		t.Error("Expected the failure to be cached")
+	}
+}
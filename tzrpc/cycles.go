@@ -0,0 +1,178 @@
+package tzrpc
+
+import (
+	"sort"
+	"sync"
+)
+
+// CycleEra of consecutive cycles that all have the same length.  Protocols
+// that change the cycle length start a new era
+type CycleEra struct {
+	FirstLevel     int64
+	FirstCycle     int64
+	BlocksPerCycle int64
+}
+
+// Mainnet's cycle eras, newest first, until the node's own are loaded
+var cycleEras = []CycleEra{
+	// Paris
+	{FirstLevel: 5726209, FirstCycle: 743, BlocksPerCycle: 24576},
+	// Mumbai
+	{FirstLevel: 3268609, FirstCycle: 593, BlocksPerCycle: 16384},
+	// Granada
+	{FirstLevel: 1589249, FirstCycle: 388, BlocksPerCycle: 8192},
+	{FirstLevel: 1, FirstCycle: 0, BlocksPerCycle: 4096},
+}
+var cycleErasLock sync.RWMutex
+
+// SetCycleEras used to map levels to cycles
+func SetCycleEras(eras []CycleEra) {
+	if len(eras) == 0 {
+		return
+	}
+	sorted := append([]CycleEra{}, eras...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].FirstLevel > sorted[j].FirstLevel })
+
+	cycleErasLock.Lock()
+	defer cycleErasLock.Unlock()
+	cycleEras = sorted
+}
+
+// LoadCycleEras from the node.  Nodes that predate Granada, which first
+// changed the cycle length, or that don't serve their raw context, only give
+// the current era, from the head's cycle and the protocol's constants
+func LoadCycleEras() error {
+	eras, err := GetCycleEras("head")
+	if err == nil {
+		SetCycleEras(eras)
+		return nil
+	}
+
+	bootstrapped, err := GetBootstrapped()
+	if err != nil {
+		return err
+	}
+	head, err := GetBlock(bootstrapped.Block, 0)
+	if err != nil {
+		return err
+	}
+	constants, err := GetConstants(head.Hash())
+	if err != nil {
+		return err
+	}
+	position, ok := head.cyclePosition()
+	if !ok || constants.BlocksPerCycle <= 0 {
+		return nil
+	}
+	current := CycleEra{
+		FirstLevel:     head.Level() - position,
+		FirstCycle:     head.Cycle(),
+		BlocksPerCycle: constants.BlocksPerCycle,
+	}
+
+	// Keep the earlier eras the current one doesn't overlap
+	cycleErasLock.RLock()
+	eras = []CycleEra{current}
+	for _, era := range cycleEras {
+		if era.FirstLevel < current.FirstLevel && era.FirstCycle < current.FirstCycle {
+			eras = append(eras, era)
+		}
+	}
+	cycleErasLock.RUnlock()
+	SetCycleEras(eras)
+	return nil
+}
+
+// GetCycleEras in the context of the block with `blockID`, a hash, level or
+// `head`
+func GetCycleEras(blockID string) ([]CycleEra, error) {
+	var data []map[string]interface{}
+	err := getRights("cycle_eras", "chains/main/blocks/"+blockID+"/context/raw/json/cycle_eras", &data)
+	if err != nil {
+		return nil, err
+	}
+	eras := []CycleEra{}
+	for _, e := range data {
+		eras = append(eras, CycleEra{
+			FirstLevel:     toInt64(e["first_level"]),
+			FirstCycle:     toInt64(e["first_cycle"]),
+			BlocksPerCycle: toInt64(e["blocks_per_cycle"]),
+		})
+	}
+	return eras, nil
+}
+
+// Cycle containing `level`
+func Cycle(level int64) int64 {
+	era := levelEra(level)
+	if level < era.FirstLevel {
+		return 0
+	}
+	return era.FirstCycle + (level-era.FirstLevel)/era.BlocksPerCycle
+}
+
+// CycleLevels returns the first and last level of `cycle`
+func CycleLevels(cycle int64) (int64, int64) {
+	era := cycleEra(cycle)
+	first := era.FirstLevel + (cycle-era.FirstCycle)*era.BlocksPerCycle
+	return first, first + era.BlocksPerCycle - 1
+}
+
+// levelEra containing `level`
+func levelEra(level int64) CycleEra {
+	cycleErasLock.RLock()
+	defer cycleErasLock.RUnlock()
+	for _, era := range cycleEras {
+		if level >= era.FirstLevel {
+			return era
+		}
+	}
+	return cycleEras[len(cycleEras)-1]
+}
+
+// cycleEra containing `cycle`
+func cycleEra(cycle int64) CycleEra {
+	cycleErasLock.RLock()
+	defer cycleErasLock.RUnlock()
+	for _, era := range cycleEras {
+		if cycle >= era.FirstCycle {
+			return era
+		}
+	}
+	return cycleEras[len(cycleEras)-1]
+}
+
+// levelInfo from the block's metadata, named `level` before Granada
+func (block *Block) levelInfo() (map[string]interface{}, bool) {
+	metadata, ok := block.data["metadata"].(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	for _, field := range []string{"level_info", "level"} {
+		if info, ok := metadata[field].(map[string]interface{}); ok {
+			return info, true
+		}
+	}
+	return nil, false
+}
+
+// cyclePosition of the block within its cycle, from its metadata
+func (block *Block) cyclePosition() (int64, bool) {
+	info, ok := block.levelInfo()
+	if !ok {
+		return 0, false
+	}
+	position, ok := info["cycle_position"]
+	return toInt64(position), ok
+}
+
+// Cycle of the block from its metadata, falling back to the cycle eras for
+// blocks without it
+func (block *Block) Cycle() int64 {
+	if info, ok := block.levelInfo(); ok {
+		if cycle, ok := info["cycle"]; ok {
+			return toInt64(cycle)
+		}
+	}
+	return Cycle(block.Level())
+}
@@ -0,0 +1,109 @@
+package tzrpc
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+)
+
+// Delegate registration, with amounts in mutez
+type Delegate struct {
+	// Balance including frozen deposits, its full balance on Tenderbake
+	Balance int64
+	// FrozenBalance of deposits, fees and rewards, or Tenderbake's current
+	// frozen deposits
+	FrozenBalance int64
+	// FrozenBalanceByCycle of deposits, fees and rewards, each released
+	// `preserved_cycles` after the cycle they were frozen in
+	FrozenBalanceByCycle map[int64]int64
+	StakingBalance       int64
+	DelegatedBalance     int64
+	DelegatedContracts   []string
+	Deactivated          bool
+	// GracePeriod is the last cycle the delegate stays active without baking
+	// or endorsing
+	GracePeriod int64
+}
+
+// GetDelegate at the block with `blockID`, a hash, level or `head`. Schema
+// defined here:
+// https://tezos.gitlab.io/alphanet/api/rpc.html#get-block-id-context-delegates-pkh
+func GetDelegate(pkh string, blockID string) (*Delegate, error) {
+	// Get Payload
+	resp, err := get(http.DefaultClient, "delegate", fmt.Sprintf("chains/main/blocks/%v/context/delegates/%v", blockID, pkh))
+	if err != nil {
+		logger.WithField("endpoint", "delegate").WithError(err).Error("Unable to query endpoint")
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		logger.WithField("endpoint", "delegate").WithError(err).Error("Unable to read response")
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		err := fmt.Errorf("Unexpected status %v from delegate: %v", resp.StatusCode, string(body))
+		logger.WithField("endpoint", "delegate").WithError(err).Error("Unable to query endpoint")
+		return nil, err
+	}
+
+	delegate, err := parseDelegate(body)
+	if err != nil {
+		logger.WithField("endpoint", "delegate").WithError(err).Error("Unable to parse json payload")
+		return nil, err
+	}
+	return delegate, nil
+}
+
+func parseDelegate(body []byte) (*Delegate, error) {
+	var data map[string]interface{}
+	if err := json.Unmarshal(body, &data); err != nil {
+		return nil, err
+	}
+	d := Delegate{
+		Balance:          toInt64(firstOf(data, "balance", "full_balance", "own_full_balance")),
+		FrozenBalance:    toInt64(firstOf(data, "frozen_balance", "current_frozen_deposits", "total_staked")),
+		StakingBalance:   toInt64(data["staking_balance"]),
+		DelegatedBalance: toInt64(data["delegated_balance"]),
+		GracePeriod:      toInt64(data["grace_period"]),
+
+		FrozenBalanceByCycle: map[int64]int64{},
+	}
+
+	// Quebec only reports the staked and delegated parts of the staking balance
+	if _, ok := data["staking_balance"]; !ok {
+		d.StakingBalance = toInt64(data["total_staked"]) + toInt64(data["total_delegated"])
+	}
+	if _, ok := data["delegated_balance"]; !ok {
+		d.DelegatedBalance = d.StakingBalance - d.Balance
+	}
+
+	d.Deactivated, _ = data["deactivated"].(bool)
+	if contracts, ok := firstOf(data, "delegated_contracts", "delegators").([]interface{}); ok {
+		for _, c := range contracts {
+			if contract, ok := c.(string); ok {
+				d.DelegatedContracts = append(d.DelegatedContracts, contract)
+			}
+		}
+	}
+	if frozen, ok := data["frozen_balance_by_cycle"].([]interface{}); ok {
+		for _, f := range frozen {
+			typedFrozen := f.(map[string]interface{})
+			cycle := toInt64(typedFrozen["cycle"])
+			d.FrozenBalanceByCycle[cycle] += toInt64(typedFrozen["deposit"]) + toInt64(typedFrozen["fees"]) + toInt64(typedFrozen["rewards"])
+		}
+	}
+	return &d, nil
+}
+
+// firstOf the `keys` present in `data`, for fields protocols have renamed
+func firstOf(data map[string]interface{}, keys ...string) interface{} {
+	for _, key := range keys {
+		if value, ok := data[key]; ok {
+			return value
+		}
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package tzrpc
+
+import (
+	"testing"
+)
+
+func TestParseDelegate(t *testing.T) {
+	delegate, err := parseDelegate([]byte(`{
+		"balance": "12000000000",
+		"frozen_balance": "3000000000",
+		"frozen_balance_by_cycle": [
+			{"cycle": 201, "deposit": "1000000000", "fees": "1000", "rewards": "30000000"},
+			{"cycle": 202, "deposit": "1900000000", "fees": "0", "rewards": "69999000"}
+		],
+		"staking_balance": "90000000000",
+		"delegated_contracts": ["KT1a", "tz1b"],
+		"delegated_balance": "78000000000",
+		"deactivated": false,
+		"grace_period": 206
+	}`))
+	check(err)
+
+	if delegate.Balance != 12000000000 || delegate.StakingBalance != 90000000000 {
+		t.Error("Incorrect balances", delegate)
+	}
+	if delegate.Deactivated || delegate.GracePeriod != 206 {
+		t.Error("Incorrect activity", delegate)
+	}
+	if delegate.FrozenBalanceByCycle[201] != 1030001000 || delegate.FrozenBalanceByCycle[202] != 1969999000 {
+		t.Error("Incorrect frozen balances", delegate.FrozenBalanceByCycle)
+	}
+	if len(delegate.DelegatedContracts) != 2 {
+		t.Error("Expected 2 delegated contracts but found", len(delegate.DelegatedContracts))
+	}
+}
+
+func TestParseQuebecDelegate(t *testing.T) {
+	delegate, err := parseDelegate([]byte(`{
+		"deactivated": false,
+		"is_forbidden": false,
+		"grace_period": 820,
+		"total_staked": "250000000000",
+		"total_delegated": "1750000000000",
+		"own_full_balance": "260000000000",
+		"own_staked": "200000000000",
+		"own_delegated": "60000000000",
+		"external_staked": "50000000000",
+		"external_delegated": "1690000000000",
+		"delegators": ["tz1baker", "tz1b", "KT1c"],
+		"baking_power": "833333333333"
+	}`))
+	check(err)
+
+	if delegate.Balance != 260000000000 || delegate.FrozenBalance != 250000000000 {
+		t.Error("Incorrect balances", delegate)
+	}
+	if delegate.StakingBalance != 2000000000000 || delegate.DelegatedBalance != 1740000000000 {
+		t.Error("Incorrect staking balance", delegate)
+	}
+	if delegate.Deactivated || delegate.GracePeriod != 820 {
+		t.Error("Incorrect activity", delegate)
+	}
+	if len(delegate.DelegatedContracts) != 3 {
+		t.Error("Expected 3 delegators but found", len(delegate.DelegatedContracts))
+	}
+}
@@ -0,0 +1,36 @@
+package tzrpc
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+)
+
+// GetDelegatedContracts of this delegate at the block with `blockID`, a hash,
+// level or `head`. Schema defined here:
+// https://tezos.gitlab.io/alphanet/api/rpc.html#get-block-id-context-delegates-pkh-delegated-contracts
+func GetDelegatedContracts(pkh string, blockID string) ([]string, error) {
+	// Get Payload
+	resp, err := get(http.DefaultClient, "delegated_contracts", fmt.Sprintf("chains/main/blocks/%v/context/delegates/%v/delegated_contracts", blockID, pkh))
+	if err != nil {
+		logger.WithField("endpoint", "delegated_contracts").WithError(err).Error("Unable to query endpoint")
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		logger.WithField("endpoint", "delegated_contracts").WithError(err).Error("Unable to read response")
+		return nil, err
+	}
+
+	// Parse Body
+	var contracts []string
+	err = json.Unmarshal(body, &contracts)
+	if err != nil {
+		logger.WithField("endpoint", "delegated_contracts").WithError(err).Error("Unable to parse json payload")
+		return nil, err
+	}
+	return contracts, nil
+}
@@ -1,12 +1,7 @@
 package tzrpc
 
 import (
-	"encoding/json"
 	"fmt"
-	"io/ioutil"
-	"log"
-	"net/http"
-	"os"
 )
 
 // EndorsingRights on the protocol
@@ -14,44 +9,34 @@ type EndorsingRights struct {
 	data []map[string]interface{}
 }
 
-// GetEndorsingRights from the network
+// GetEndorsingRights from the network, in the context of the block at `level`
+// if it exists so archived levels can be queried too, otherwise the head
 // Schema defined here: https://tezos.gitlab.io/alphanet/api/rpc.html#get-block-id-helpers-endorsing-rights
 func GetEndorsingRights(level int64) (*EndorsingRights, error) {
-	// Get Payload
-	resp, err := http.Get(fmt.Sprintf("%v/chains/main/blocks/head/helpers/endorsing_rights?level=%v", os.Getenv("NODE_URL"), level))
-	if err != nil {
-		log.Println("[Endorsing Rights] Unable to query endpoint")
-		return nil, err
-	}
-
-	defer resp.Body.Close()
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		log.Println("[Endorsing Rights] Unable to read response")
-		return nil, err
+	if cached, ok := endorsingRightsCache.get(level); ok {
+		if err, ok := cached.(error); ok {
+			return nil, err
+		}
+		return cached.(*EndorsingRights), nil
 	}
 
-	// Parse Body
-	// var parsed []map[string]interface{}
 	er := EndorsingRights{}
-	err = json.Unmarshal(body, &er.data)
+	err := getEndorsingRights(rightsContext(level), fmt.Sprintf("level=%v", level), &er.data)
 	if err != nil {
-		log.Println("[Endorsing Rights] Unable to parse json payload")
+		endorsingRightsCache.add(level, err, rightsRetry)
 		return nil, err
 	}
 
+	endorsingRightsCache.add(level, &er, 0)
 	return &er, nil
 }
 
-// Slots that the delegate is allowed to vote
+// Slots that the delegate is allowed to vote.  On Tenderbake protocols these
+// stand in for its first slot and power
 func (er *EndorsingRights) Slots(delegate string) []int64 {
 	var slots []int64
 	for _, rights := range er.data {
-		if rights["delegate"] == delegate {
-			for _, slot := range rights["slots"].([]interface{}) {
-				slots = append(slots, int64(slot.(float64)))
-			}
-		}
+		slots = append(slots, rightsSlots(rights, delegate)...)
 	}
 	return slots
 }
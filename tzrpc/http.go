@@ -0,0 +1,40 @@
+package tzrpc
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"gitlab.com/polychainlabs/tezos-network-monitor/logging"
+	"gitlab.com/polychainlabs/tezos-network-monitor/metrics"
+	"golang.org/x/time/rate"
+)
+
+var logger = logging.Component("tzrpc")
+
+// Shared by every caller so concurrent checks can't overwhelm the node
+var limiter = rate.NewLimiter(rate.Inf, 1)
+
+// SetRateLimit of requests per second sent to the node.  Zero or less
+// removes the limit
+func SetRateLimit(perSecond float64) {
+	if perSecond <= 0 {
+		limiter.SetLimit(rate.Inf)
+		return
+	}
+	limiter.SetLimit(rate.Limit(perSecond))
+}
+
+// get a path from the node, recording the request latency under `endpoint`
+func get(client *http.Client, endpoint string, path string) (*http.Response, error) {
+	err := limiter.Wait(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	resp, err := client.Get(fmt.Sprintf("%v/%v", os.Getenv("NODE_URL"), path))
+	metrics.RPCDuration.WithLabelValues(endpoint).Observe(time.Since(start).Seconds())
+	return resp, err
+}
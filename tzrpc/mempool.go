@@ -0,0 +1,321 @@
+package tzrpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strconv"
+)
+
+// Classifications of pending operations by the node's mempool
+const (
+	MempoolApplied       = "applied"
+	MempoolValidated     = "validated"
+	MempoolRefused       = "refused"
+	MempoolOutdated      = "outdated"
+	MempoolBranchRefused = "branch_refused"
+	MempoolBranchDelayed = "branch_delayed"
+	MempoolUnprocessed   = "unprocessed"
+)
+
+// MempoolStatuses every pending operation is classified under
+var MempoolStatuses = []string{
+	MempoolApplied, MempoolValidated, MempoolRefused, MempoolOutdated,
+	MempoolBranchRefused, MempoolBranchDelayed, MempoolUnprocessed,
+}
+
+// PendingOperation in the mempool, not yet included in a block.  Unlike
+// included operations, these carry no metadata
+type PendingOperation struct {
+	Hash   string
+	Status string
+	// Errors ids the node refused or delayed the operation with
+	Errors   []string
+	contents []map[string]interface{}
+}
+
+// ConsensusOperation pending in the mempool.  Without metadata, its delegate
+// can only be found from the rights at `Level`
+type ConsensusOperation struct {
+	Kind  string
+	Level int64
+	// Slot of the endorsement, the delegate's first slot on Tenderbake
+	// protocols, or -1 if the protocol doesn't include it
+	Slot int64
+}
+
+// Evidence of double signing pending in the mempool.  Without metadata, the
+// accused is found from the rights at `Level`
+type Evidence struct {
+	Kind  string
+	Level int64
+	// Priority or round of a double baked block
+	Priority int64
+	// Slot of double signed consensus operations, or -1 if unknown
+	Slot int64
+}
+
+// GetPendingOperations in the node's mempool, by classification. Schema
+// defined here:
+// https://tezos.gitlab.io/alphanet/api/rpc.html#get-chains-chain-id-mempool-pending-operations
+func GetPendingOperations() ([]PendingOperation, error) {
+	resp, err := get(http.DefaultClient, "pending_operations", "chains/main/mempool/pending_operations")
+	if err != nil {
+		logger.WithField("endpoint", "pending_operations").WithError(err).Error("Unable to query endpoint")
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		logger.WithField("endpoint", "pending_operations").WithError(err).Error("Unable to read response")
+		return nil, err
+	}
+
+	operations, err := parsePendingOperations(body)
+	if err != nil {
+		logger.WithField("endpoint", "pending_operations").WithError(err).Error("Unable to parse json payload")
+		return nil, err
+	}
+	return operations, nil
+}
+
+// parsePendingOperations of every classification.  Older nodes list errored
+// operations as `[hash, operation]` pairs, newer ones as operations with a
+// `hash`
+func parsePendingOperations(body []byte) ([]PendingOperation, error) {
+	var data map[string]interface{}
+	if err := json.Unmarshal(body, &data); err != nil {
+		return nil, err
+	}
+	operations := []PendingOperation{}
+	for _, status := range MempoolStatuses {
+		list, _ := data[status].([]interface{})
+		for _, entry := range list {
+			switch typed := entry.(type) {
+			case map[string]interface{}:
+				operations = append(operations, pendingOperation(typed, status))
+			case []interface{}:
+				if len(typed) != 2 {
+					continue
+				}
+				operation, ok := typed[1].(map[string]interface{})
+				if !ok {
+					continue
+				}
+				if _, ok := operation["hash"]; !ok {
+					operation["hash"] = typed[0]
+				}
+				operations = append(operations, pendingOperation(operation, status))
+			}
+		}
+	}
+	return operations, nil
+}
+
+func pendingOperation(data map[string]interface{}, status string) PendingOperation {
+	op := PendingOperation{Status: status}
+	op.Hash, _ = data["hash"].(string)
+	if contents, ok := data["contents"].([]interface{}); ok {
+		for _, c := range contents {
+			if typed, ok := c.(map[string]interface{}); ok {
+				op.contents = append(op.contents, typed)
+			}
+		}
+	}
+	if errors, ok := data["error"].([]interface{}); ok {
+		for _, e := range errors {
+			if typed, ok := e.(map[string]interface{}); ok {
+				id, _ := typed["id"].(string)
+				op.Errors = append(op.Errors, id)
+			}
+		}
+	}
+	return op
+}
+
+// MonitorOperations streams operations as the node's mempool applies them,
+// calling `handle` for each until the stream ends, e.g. on a new head, or
+// `ctx` is done. Schema defined here:
+// https://tezos.gitlab.io/alphanet/api/rpc.html#get-chains-chain-id-mempool-monitor-operations
+func MonitorOperations(ctx context.Context, handle func(PendingOperation)) error {
+	err := limiter.Wait(ctx)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest("GET", fmt.Sprintf("%v/chains/main/mempool/monitor_operations", os.Getenv("NODE_URL")), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		logger.WithField("endpoint", "monitor_operations").WithError(err).Error("Unable to query endpoint")
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Unexpected status %v from monitor_operations", resp.StatusCode)
+	}
+	return decodeOperationStream(resp.Body, handle)
+}
+
+// decodeOperationStream of json arrays of applied operations
+func decodeOperationStream(r io.Reader, handle func(PendingOperation)) error {
+	decoder := json.NewDecoder(r)
+	for {
+		var batch []map[string]interface{}
+		err := decoder.Decode(&batch)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		for _, operation := range batch {
+			handle(pendingOperation(operation, MempoolApplied))
+		}
+	}
+}
+
+// Transactions in the operation
+func (op *PendingOperation) Transactions() []Transaction {
+	tx := []Transaction{}
+	for _, c := range op.contents {
+		if c["kind"] == "transaction" {
+			tx = append(tx, transactionFrom(c))
+		}
+	}
+	return tx
+}
+
+// ConsensusOperations in the operation: endorsements, attestations and their
+// preendorsement and preattestation counterparts
+func (op *PendingOperation) ConsensusOperations() []ConsensusOperation {
+	consensus := []ConsensusOperation{}
+	for _, c := range op.contents {
+		if e, ok := consensusOperation(c); ok {
+			consensus = append(consensus, e)
+		}
+	}
+	return consensus
+}
+
+// consensusOperation from operation contents, if they're one
+func consensusOperation(c map[string]interface{}) (ConsensusOperation, bool) {
+	kind, _ := c["kind"].(string)
+	switch kind {
+	case "endorsement", "attestation", "attestation_with_dal", "preendorsement", "preattestation":
+		return ConsensusOperation{Kind: kind, Level: toInt64(c["level"]), Slot: slotOf(c)}, true
+	case "endorsement_with_slot":
+		e := ConsensusOperation{Kind: kind, Slot: slotOf(c)}
+		if wrapped, ok := c["endorsement"].(map[string]interface{}); ok {
+			if inner, ok := wrapped["operations"].(map[string]interface{}); ok {
+				e.Level = toInt64(inner["level"])
+			}
+		}
+		return e, true
+	}
+	return ConsensusOperation{}, false
+}
+
+// Evidence of double signing in the operation
+func (op *PendingOperation) Evidence() []Evidence {
+	evidence := []Evidence{}
+	for _, c := range op.contents {
+		kind, _ := c["kind"].(string)
+		if kind == "double_baking_evidence" {
+			header, _ := c["bh1"].(map[string]interface{})
+			e := Evidence{Kind: kind, Level: toInt64(header["level"]), Slot: -1}
+			if priority, ok := header["priority"].(float64); ok {
+				e.Priority = int64(priority)
+			} else {
+				e.Priority = fitnessRound(header)
+			}
+			evidence = append(evidence, e)
+			continue
+		}
+		if !containsKind(doubleEndorsementKinds, kind) {
+			continue
+		}
+		e := Evidence{Kind: kind, Slot: -1}
+		if signed, ok := c["op1"].(map[string]interface{}); ok {
+			if inner, ok := signed["operations"].(map[string]interface{}); ok {
+				e.Level = toInt64(inner["level"])
+				e.Slot = slotOf(inner)
+				// Edo wrapped the endorsement with its slot
+				if wrapped, ok := inner["endorsement"].(map[string]interface{}); ok {
+					if endorsement, ok := wrapped["operations"].(map[string]interface{}); ok {
+						e.Level = toInt64(endorsement["level"])
+					}
+				}
+			}
+		}
+		if e.Slot < 0 {
+			e.Slot = slotOf(c)
+		}
+		evidence = append(evidence, e)
+	}
+	return evidence
+}
+
+// slotOf a consensus operation, or -1 if it doesn't include one
+func slotOf(data map[string]interface{}) int64 {
+	if _, ok := data["slot"]; !ok {
+		return -1
+	}
+	return toInt64(data["slot"])
+}
+
+// Delegate with rights to bake at `priority`, or its round, or "" if none
+func (br *BakingRights) Delegate(priority int64) string {
+	for _, rights := range br.data {
+		if rightsRound(rights) == priority {
+			delegate, _ := rights["delegate"].(string)
+			return ResolveDelegate(delegate)
+		}
+	}
+	return ""
+}
+
+// SlotDelegate owning `slot`, which identifies a delegate by its first slot
+// on Tenderbake protocols, or "" if none
+func (er *EndorsingRights) SlotDelegate(slot int64) string {
+	for _, rights := range er.data {
+		if delegates, ok := rights["delegates"].([]interface{}); ok {
+			for _, d := range delegates {
+				typed, ok := d.(map[string]interface{})
+				if ok && toInt64(typed["first_slot"]) == slot {
+					delegate, _ := typed["delegate"].(string)
+					return ResolveDelegate(delegate)
+				}
+			}
+			continue
+		}
+		slots, _ := rights["slots"].([]interface{})
+		for _, s := range slots {
+			if s, ok := s.(float64); ok && int64(s) == slot {
+				delegate, _ := rights["delegate"].(string)
+				return ResolveDelegate(delegate)
+			}
+		}
+	}
+	return ""
+}
+
+// transactionFrom the contents of a transaction operation.  Mempool
+// operations aren't validated yet, so missing fields are left empty rather
+// than trusted
+func transactionFrom(contents map[string]interface{}) Transaction {
+	tx := Transaction{}
+	tx.Source, _ = contents["source"].(string)
+	tx.Destination, _ = contents["destination"].(string)
+	amount, _ := contents["amount"].(string)
+	tx.Amount, _ = strconv.Atoi(amount)
+	fee, _ := contents["fee"].(string)
+	tx.Fee, _ = strconv.Atoi(fee)
+	return tx
+}
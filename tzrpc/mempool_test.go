@@ -0,0 +1,141 @@
+package tzrpc
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestParsePendingOperations(t *testing.T) {
+	// Older nodes pair errored operations with their hash
+	operations, err := parsePendingOperations([]byte(`{
+		"applied": [
+			{"hash": "opApplied", "branch": "BL1", "contents": [
+				{"kind": "transaction", "source": "tz1ours", "destination": "tz1thief", "amount": "5000000", "fee": "1420"}
+			]}
+		],
+		"refused": [
+			["opRefused", {"branch": "BL1", "contents": [
+				{"kind": "attestation", "slot": 12, "level": 300, "round": 0}
+			], "error": [{"kind": "temporary", "id": "proto.019-PtParisB.validate.consensus_operation_for_old_level"}]}]
+		],
+		"branch_delayed": [],
+		"unprocessed": []
+	}`))
+	check(err)
+	if len(operations) != 2 {
+		t.Fatal("Expected 2 pending operations, found", len(operations))
+	}
+	applied, refused := operations[0], operations[1]
+	if applied.Hash != "opApplied" || applied.Status != MempoolApplied {
+		t.Errorf("Incorrect applied operation %+v", applied)
+	}
+	if tx := applied.Transactions(); len(tx) != 1 || tx[0].Destination != "tz1thief" || tx[0].Amount != 5000000 {
+		t.Errorf("Incorrect transactions %+v", tx)
+	}
+	if refused.Hash != "opRefused" || refused.Status != MempoolRefused || len(refused.Errors) != 1 ||
+		!strings.HasSuffix(refused.Errors[0], "consensus_operation_for_old_level") {
+		t.Errorf("Incorrect refused operation %+v", refused)
+	}
+	if c := refused.ConsensusOperations(); len(c) != 1 || c[0].Level != 300 || c[0].Slot != 12 {
+		t.Errorf("Incorrect consensus operations %+v", c)
+	}
+
+	// Newer nodes include the hash in each operation
+	operations, err = parsePendingOperations([]byte(`{
+		"validated": [],
+		"branch_delayed": [
+			{"hash": "opDelayed", "contents": [
+				{"kind": "double_attestation_evidence",
+				 "op1": {"branch": "BL1", "operations": {"kind": "attestation", "slot": 3, "level": 290, "round": 1}},
+				 "op2": {"branch": "BL1", "operations": {"kind": "attestation", "slot": 3, "level": 290, "round": 1}}},
+				{"kind": "double_baking_evidence",
+				 "bh1": {"level": 280, "fitness": ["02", "00000118", "", "ffffffff", "00000002"]}}
+			], "error": []}
+		]
+	}`))
+	check(err)
+	if len(operations) != 1 || operations[0].Hash != "opDelayed" || operations[0].Status != MempoolBranchDelayed {
+		t.Fatalf("Incorrect pending operations %+v", operations)
+	}
+	evidence := operations[0].Evidence()
+	if len(evidence) != 2 {
+		t.Fatal("Expected 2 pieces of evidence, found", len(evidence))
+	}
+	if evidence[0].Level != 290 || evidence[0].Slot != 3 {
+		t.Errorf("Incorrect double attestation evidence %+v", evidence[0])
+	}
+	if evidence[1].Level != 280 || evidence[1].Priority != 2 {
+		t.Errorf("Incorrect double baking evidence %+v", evidence[1])
+	}
+}
+
+func TestMalformedPendingOperations(t *testing.T) {
+	operations, err := parsePendingOperations([]byte(`{
+		"applied": [
+			{"hash": "opMalformed", "contents": [
+				{"kind": "transaction", "source": 7, "amount": 5000000},
+				{"kind": "attestation", "slot": "x"},
+				"transaction"
+			]}
+		]
+	}`))
+	check(err)
+	if len(operations) != 1 {
+		t.Fatal("Expected 1 pending operation, found", len(operations))
+	}
+	if tx := operations[0].Transactions(); len(tx) != 1 || tx[0].Source != "" || tx[0].Amount != 0 {
+		t.Errorf("Expected malformed fields to be left empty %+v", tx)
+	}
+
+	var endorsing []map[string]interface{}
+	check(json.Unmarshal([]byte(`[{"level": 290, "delegates": ["tz1a"]}, {"level": 290, "delegate": "tz1b", "slots": ["4"]}]`), &endorsing))
+	er := EndorsingRights{data: endorsing}
+	if er.SlotDelegate(4) != "" {
+		t.Error("Expected no owner of malformed rights")
+	}
+}
+
+func TestOperationStream(t *testing.T) {
+	stream := `[{"hash": "op1", "contents": [{"kind": "attestation", "slot": 1, "level": 10}]}]
+		[{"hash": "op2", "contents": []}, {"hash": "op3", "contents": []}]`
+	hashes := []string{}
+	err := decodeOperationStream(strings.NewReader(stream), func(op PendingOperation) {
+		hashes = append(hashes, op.Hash)
+	})
+	check(err)
+	if strings.Join(hashes, ",") != "op1,op2,op3" {
+		t.Error("Incorrect streamed operations", hashes)
+	}
+}
+
+func TestRightsOwners(t *testing.T) {
+	var baking []map[string]interface{}
+	check(json.Unmarshal([]byte(`[
+		{"level": 280, "delegate": "tz1a", "round": 0},
+		{"level": 280, "delegate": "tz1b", "round": 2}
+	]`), &baking))
+	br := BakingRights{data: baking}
+	if br.Delegate(2) != "tz1b" || br.Delegate(5) != "" {
+		t.Error("Incorrect baking rights owners")
+	}
+
+	var endorsing []map[string]interface{}
+	check(json.Unmarshal([]byte(`[
+		{"level": 290, "delegates": [
+			{"delegate": "tz1a", "first_slot": 0, "attestation_power": 3},
+			{"delegate": "tz1b", "first_slot": 3, "attestation_power": 1}
+		]}
+	]`), &endorsing))
+	er := EndorsingRights{data: endorsing}
+	if er.SlotDelegate(3) != "tz1b" || er.SlotDelegate(1) != "" {
+		t.Error("Incorrect endorsing rights owners")
+	}
+
+	var listed []map[string]interface{}
+	check(json.Unmarshal([]byte(`[{"level": 290, "delegate": "tz1c", "slots": [4, 9]}]`), &listed))
+	er = EndorsingRights{data: listed}
+	if er.SlotDelegate(9) != "tz1c" {
+		t.Error("Incorrect endorsing rights owners before Tenderbake")
+	}
+}
@@ -0,0 +1,36 @@
+package tzrpc
+
+// SeedNonceHash committed to by the baker of this block, or "" if this block
+// didn't require a commitment
+func (block *Block) SeedNonceHash() string {
+	header := block.data["header"].(map[string]interface{})
+	if hash, ok := header["seed_nonce_hash"].(string); ok {
+		return hash
+	}
+	if metadata, ok := block.data["metadata"].(map[string]interface{}); ok {
+		if hash, ok := metadata["nonce_hash"].(string); ok {
+			return hash
+		}
+	}
+	return ""
+}
+
+// SeedNonceRevelations in this block, as the levels whose nonces were revealed
+func (block *Block) SeedNonceRevelations() []int64 {
+	levels := []int64{}
+
+	operations := block.data["operations"].([]interface{})
+	for _, outer := range operations {
+		for _, inner := range outer.([]interface{}) {
+			operation := inner.(map[string]interface{})
+			contents := operation["contents"].([]interface{})
+			for _, c := range contents {
+				typedContents := c.(map[string]interface{})
+				if typedContents["kind"] == "seed_nonce_revelation" {
+					levels = append(levels, int64(typedContents["level"].(float64)))
+				}
+			}
+		}
+	}
+	return levels
+}
@@ -0,0 +1,30 @@
+package tzrpc
+
+import (
+	"testing"
+)
+
+func TestSeedNonces(t *testing.T) {
+	block, err := parseBlock([]byte(`{
+		"hash": "BLx",
+		"header": {"level": 819232, "seed_nonce_hash": "nceUFoeQDgkJCmzdMWh19ZjBYqQD3N9fe6bXQ1ZsUKKvMn7iun5Z3"},
+		"metadata": {"nonce_hash": "nceUFoeQDgkJCmzdMWh19ZjBYqQD3N9fe6bXQ1ZsUKKvMn7iun5Z3"},
+		"operations": [[], [], [{"contents": [
+			{"kind": "seed_nonce_revelation", "level": 815136, "nonce": "6f"},
+			{"kind": "seed_nonce_revelation", "level": 815168, "nonce": "70"}
+		]}], []]
+	}`))
+	check(err)
+
+	if block.SeedNonceHash() != "nceUFoeQDgkJCmzdMWh19ZjBYqQD3N9fe6bXQ1ZsUKKvMn7iun5Z3" {
+		t.Error("Incorrect seed nonce hash", block.SeedNonceHash())
+	}
+	revealed := block.SeedNonceRevelations()
+	if len(revealed) != 2 || revealed[0] != 815136 || revealed[1] != 815168 {
+		t.Error("Incorrect revealed levels", revealed)
+	}
+
+	if getBlock("../tests/double_endorsement.json").SeedNonceHash() != "" {
+		t.Error("Expected no seed nonce hash")
+	}
+}
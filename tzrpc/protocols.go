@@ -0,0 +1,130 @@
+package tzrpc
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sync"
+)
+
+// Protocols whose block and operation layout this package can parse, by hash
+var supportedProtocols = map[string]string{
+	"PsddFKi32cMJ2qPjf43Qv5GDWLDPZb3T3bF6fLKiF5HtvHNU7aP": "003_PsddFKi3",
+	"Pt24m4xiPbLDhVgVfABUjirbmda3yohdN82Sp9FeuAXJ4eV9otd": "004_Pt24m4xi",
+	"PsBABY5HQTSkA4297zNHfsZNKtxULfL18y95qb3m53QJiXGmrbU": "005_PsBABY5H",
+	"PsBabyM1eEpj6fEP6SJJ6uqbVn4yE5Z5Vv6k8H2cXzcfonQgyVq": "005_PsBabyM1",
+	"PsCARTHAGazKbHtnKfLzQg3kms52kSRpgnDY982a9oYsSXRLQEb": "006_PsCARTHA",
+	"PsDELPH1Kxsxt8f9eWbxQeRxkjfbxoqM52jvs5Y5fBxWWh4ifpo": "007_PsDELPH1",
+	"PtEdoTezd3RHSC31mpxxo1npxFjoWWcFgQtxapi51Z8TLu6v6Uq": "008_PtEdoTez",
+	"PtEdo2ZkT9oKpimTah6x2embF25oss54njMuPzkJTEi5RqfdZFA": "008_PtEdo2Zk",
+	"PsFLorenaUUuikDWvMDr6fGBRG8kt3e3D3fHoXK1j1BFRxeSH4i": "009_PsFLoren",
+	"PtGRANADsDU8R9daYKAgWnQYAJ64omN1o3KMGVCykShA97vQbvV": "010_PtGRANAD",
+	"PtHangz2aRngywmSRGGvrcTyMbbdpWdpFKuS4uMWxg2RaH9i1qx": "011_PtHangz2",
+	// Tenderbake
+	"Psithaca2MLRFYargivpo7YvUr7wUDqyxrdhC5CQq78mRvimz6A": "012_Psithaca",
+	"PtJakart2xVj7pYXJBXrqHgd82rdkLey5ZeeGwDgPp9rhQUbSqY": "013_PtJakart",
+	"PtKathmankSpLLDALzWw7CGD2j2MtyveTwboEYokqUCP4a1LxMg": "014_PtKathma",
+	"PtLimaPtLMwfNinJi9rCfDPWea8dFgTZ1MeJ9f1m2SRic6ayiwW": "015_PtLimaPt",
+	"PtMumbai2TmsJHNGRkD8v8YDbtao7BLUC3wjASn1inAKLFCjaH1": "016_PtMumbai",
+	"PtNairobiyssHuh87hEhfVBGCVrK3WnS8Z2FT4ymB5tAa4r1nQf": "017_PtNairob",
+	"ProxfordYmVfjWnRcgjWH36fW6PArwqykTFzotUxRs6gmTcZDuH": "018_Proxford",
+	"PtParisBxoLz5gzMmn3d9WBQNoPSZakgnkMC2VNuQ3KXfUtUQeZ": "019_PtParisB",
+	"PsParisCZo7KAh1Z1smVd9ZMZ1HHn5gkzbM94V3PLCpknFWhUAi": "020_PsParisC",
+	"PsQuebecnLByd3JwTiGadoG4nGWi3HYiLXUjkibeFV8dCFeVMUg": "021_PsQuebec",
+	"PsRiotumaAMotcRoDWW1bysEhQy2n1M5fy8JgRp8jjRfHGmfeA7": "022_PsRiotum",
+	"PtSeouLouXkxhg39oWzjxDWaCydNfR3RxCUrNe4Q9Ro8BTehcbh": "023_PtSeouLo",
+}
+var protocolLock sync.RWMutex
+
+// SupportProtocol `hash` under `name`, for protocols that didn't change the
+// layout we parse
+func SupportProtocol(hash string, name string) {
+	protocolLock.Lock()
+	defer protocolLock.Unlock()
+	supportedProtocols[hash] = name
+}
+
+// IsSupportedProtocol reports whether blocks of protocol `hash` can be parsed
+func IsSupportedProtocol(hash string) bool {
+	protocolLock.RLock()
+	defer protocolLock.RUnlock()
+	_, ok := supportedProtocols[hash]
+	return ok
+}
+
+// Supported reports whether the block can be parsed: its protocol is known,
+// or it has the Tenderbake layout every protocol since Ithaca has kept
+func (block *Block) Supported() bool {
+	return IsSupportedProtocol(block.Protocol()) || block.tenderbakeLayout()
+}
+
+// SupportsProtocol reports whether blocks of protocol `hash` following this
+// one can be parsed.  Unknown successors of a Tenderbake block are assumed to
+// keep its layout
+func (block *Block) SupportsProtocol(hash string) bool {
+	return IsSupportedProtocol(hash) || block.tenderbakeLayout()
+}
+
+// tenderbakeLayout of the block's header, which carries a payload hash from
+// Ithaca on
+func (block *Block) tenderbakeLayout() bool {
+	header, ok := block.data["header"].(map[string]interface{})
+	if !ok {
+		return false
+	}
+	_, ok = header["payload_hash"]
+	return ok
+}
+
+// Protocol the block was validated with
+func (block *Block) Protocol() string {
+	if metadata, ok := block.data["metadata"].(map[string]interface{}); ok {
+		if protocol, ok := metadata["protocol"].(string); ok {
+			return protocol
+		}
+	}
+	protocol, _ := block.data["protocol"].(string)
+	return protocol
+}
+
+// NextProtocol the following block will be validated with.  It only differs
+// from Protocol in the last block before an upgrade
+func (block *Block) NextProtocol() string {
+	if metadata, ok := block.data["metadata"].(map[string]interface{}); ok {
+		if protocol, ok := metadata["next_protocol"].(string); ok {
+			return protocol
+		}
+	}
+	return block.Protocol()
+}
+
+// GetCurrentProposal being voted on or awaiting activation at the block with
+// `blockID`, or "" if there is none. Schema defined here:
+// https://tezos.gitlab.io/alphanet/api/rpc.html#get-block-id-votes-current-proposal
+func GetCurrentProposal(blockID string) (string, error) {
+	resp, err := get(http.DefaultClient, "current_proposal", fmt.Sprintf("chains/main/blocks/%v/votes/current_proposal", blockID))
+	if err != nil {
+		logger.WithField("endpoint", "current_proposal").WithError(err).Error("Unable to query endpoint")
+		return "", err
+	}
+
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		logger.WithField("endpoint", "current_proposal").WithError(err).Error("Unable to read response")
+		return "", err
+	}
+
+	// Parse Body
+	var proposal *string
+	err = json.Unmarshal(body, &proposal)
+	if err != nil {
+		logger.WithField("endpoint", "current_proposal").WithError(err).Error("Unable to parse json payload")
+		return "", err
+	}
+	if proposal == nil {
+		return "", nil
+	}
+	return *proposal, nil
+}
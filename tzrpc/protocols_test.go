@@ -0,0 +1,46 @@
+package tzrpc
+
+import (
+	"testing"
+)
+
+func TestProtocol(t *testing.T) {
+	block := getBlock("../tests/double_endorsement.json")
+	if block.Protocol() != "Pt24m4xiPbLDhVgVfABUjirbmda3yohdN82Sp9FeuAXJ4eV9otd" || block.NextProtocol() != block.Protocol() {
+		t.Error("Incorrect protocols", block.Protocol(), block.NextProtocol())
+	}
+	if !IsSupportedProtocol(block.Protocol()) {
+		t.Error("Expected Athens to be supported")
+	}
+
+	SupportProtocol("PtTest", "test")
+	if !IsSupportedProtocol("PtTest") || IsSupportedProtocol("PtUnknown") {
+		t.Error("Incorrect supported protocols")
+	}
+}
+
+func TestCurrentProtocols(t *testing.T) {
+	// Mainnet's protocol since September 2025
+	if !IsSupportedProtocol("PtSeouLouXkxhg39oWzjxDWaCydNfR3RxCUrNe4Q9Ro8BTehcbh") {
+		t.Error("Expected Seoul to be supported")
+	}
+
+	// Later protocols keep the Tenderbake layout until shown otherwise
+	block, err := parseBlock([]byte(`{"hash": "BLnext", "protocol": "PtNextProtocol",
+		"header": {"level": 10000000, "payload_hash": "vh2UJ9qvkPDMAmJ7Btnh1TRYkWMzXP6dESKqa6JtkGwcrSbGyCFn"}}`))
+	check(err)
+	if IsSupportedProtocol("PtNextProtocol") || !block.Supported() || !block.SupportsProtocol("PtNextProtocol") {
+		t.Error("Expected unknown Tenderbake protocols to be parsed")
+	}
+	if old := getBlock("../tests/double_endorsement.json"); old.SupportsProtocol("PtNextProtocol") {
+		t.Error("Expected unknown successors of Emmy protocols to be unsupported")
+	}
+}
+
+func TestEndorsements(t *testing.T) {
+	block := getBlock("../tests/double_baking_2.json")
+	slots, _ := block.Endorsements("tz1NpWrAyDL9k2Lmnyxcgr9xuJakbBxdq7FB")
+	if len(slots) != 3 {
+		t.Error("Expected 3 endorsement slots but found", len(slots))
+	}
+}
@@ -4,10 +4,8 @@ import (
 	"errors"
 	"fmt"
 	"io/ioutil"
-	"log"
 	"math/big"
 	"net/http"
-	"os"
 	"strings"
 )
 
@@ -15,17 +13,22 @@ import (
 // amount is in *full* Tez. Schema defined here:
 // https://tezos.gitlab.io/alphanet/api/rpc.html#get-block-id-context-delegates-pkh-staking-balance
 func GetStakingBalance(pkh string) (*big.Int, error) {
+	return GetStakingBalanceAt(pkh, "head")
+}
+
+// GetStakingBalanceAt the block with `blockID`, a hash, level or `head`
+func GetStakingBalanceAt(pkh string, blockID string) (*big.Int, error) {
 	// Get Payload
-	resp, err := http.Get(fmt.Sprintf("%v/chains/main/blocks/head/context/delegates/%v/staking_balance", os.Getenv("NODE_URL"), pkh))
+	resp, err := get(http.DefaultClient, "staking_balance", fmt.Sprintf("chains/main/blocks/%v/context/delegates/%v/staking_balance", blockID, pkh))
 	if err != nil {
-		log.Println("[Staking Balance] Unable to query endpoint")
+		logger.WithField("endpoint", "staking_balance").WithError(err).Error("Unable to query endpoint")
 		return nil, err
 	}
 
 	defer resp.Body.Close()
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		log.Println("[Staking Balance] Unable to read response")
+		logger.WithField("endpoint", "staking_balance").WithError(err).Error("Unable to read response")
 		return nil, err
 	}
 
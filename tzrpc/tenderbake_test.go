@@ -0,0 +1,90 @@
+package tzrpc
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestTenderbakeBlock(t *testing.T) {
+	block := getBlock("../tests/tenderbake_block.json")
+	if block.Round() != 2 || block.BakerPriority() != 2 || block.PayloadRound() != 1 {
+		t.Error("Incorrect rounds", block.Round(), block.BakerPriority(), block.PayloadRound())
+	}
+
+	slots, _ := block.Endorsements("tz1TenderbakeOurs111111111111111111")
+	if len(slots) != 12 || slots[0] != 5 {
+		t.Error("Expected 12 attested slots from slot 5, found", slots)
+	}
+	slots, _ = block.Endorsements("tz1TenderbakeOther11111111111111111")
+	if len(slots) != 3 || slots[0] != 40 {
+		t.Error("Expected 3 endorsed slots from slot 40, found", slots)
+	}
+	if preattested := block.Preattestations("tz1TenderbakeOurs111111111111111111"); len(preattested) != 12 {
+		t.Error("Expected 12 preattested slots, found", len(preattested))
+	}
+}
+
+func TestTenderbakeDenunciations(t *testing.T) {
+	block := getBlock("../tests/tenderbake_block.json")
+
+	doubles := block.DoubleEndorsements()
+	if len(doubles) != 1 {
+		t.Fatal("Expected 1 double preattestation, found", len(doubles))
+	}
+	d := doubles[0]
+	if d.Kind != "double_preattestation_evidence" || d.SlashedEndorser != "tz1TenderbakeSlashed11111111111111" ||
+		d.RewardedBaker != "tz1TenderbakeAccuser11111111111111" || d.Level != 5726100 {
+		t.Errorf("Incorrect double preattestation %+v", d)
+	}
+
+	bakings := block.DoubleBakings()
+	if len(bakings) != 1 {
+		t.Fatal("Expected 1 double baking, found", len(bakings))
+	}
+	b := bakings[0]
+	if b.SlashedBaker != "tz1TenderbakeDoubleBaker111111111" || b.RewardedBaker != "tz1TenderbakeAccuser11111111111111" ||
+		b.SlashedAmount != 6000000000 || b.Level != 5726150 {
+		t.Errorf("Incorrect double baking %+v", b)
+	}
+}
+
+func TestTenderbakeRights(t *testing.T) {
+	var baking []map[string]interface{}
+	check(json.Unmarshal([]byte(`[
+		{"level": 100, "delegate": "tz1a", "round": 0},
+		{"level": 100, "delegate": "tz1b", "round": 3}
+	]`), &baking))
+	br := BakingRights{data: baking}
+	if br.GetBakingPriority("tz1b") != 3 || br.GetBakingPriority("tz1c") != -1 {
+		t.Error("Incorrect baking rounds")
+	}
+
+	var endorsing []map[string]interface{}
+	check(json.Unmarshal([]byte(`[
+		{"level": 100, "delegates": [
+			{"delegate": "tz1a", "first_slot": 2, "endorsing_power": 4},
+			{"delegate": "tz1b", "first_slot": 0, "attestation_power": 1}
+		]}
+	]`), &endorsing))
+	er := EndorsingRights{data: endorsing}
+	if slots := er.Slots("tz1a"); len(slots) != 4 || slots[0] != 2 {
+		t.Error("Expected 4 slots from slot 2, found", slots)
+	}
+	if slots := er.Slots("tz1b"); len(slots) != 1 {
+		t.Error("Expected 1 slot, found", slots)
+	}
+	if slots := er.Slots("tz1c"); len(slots) != 0 {
+		t.Error("Expected no slots, found", slots)
+	}
+}
+
+func TestEndorsedLevels(t *testing.T) {
+	block := getBlock("../tests/tenderbake_block.json")
+	levels := block.EndorsedLevels("tz1TenderbakeOurs111111111111111111")
+	if len(levels) != 1 || levels[0] != 5726208 {
+		t.Error("Expected the predecessor's level to be endorsed, found", levels)
+	}
+	if levels := block.EndorsedLevels("tz1TenderbakeMissing111111111111111"); len(levels) != 0 {
+		t.Error("Expected no endorsed levels, found", levels)
+	}
+}
@@ -0,0 +1,203 @@
+package tzrpc
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+)
+
+// VotingPeriod the chain is in
+type VotingPeriod struct {
+	Index         int64
+	Kind          string
+	StartPosition int64
+	Position      int64
+	// Remaining blocks in the period, or -1 if the node doesn't say
+	Remaining int64
+}
+
+// Voting reports whether bakers are expected to cast ballots in this period
+func (p *VotingPeriod) Voting() bool {
+	switch p.Kind {
+	case "testing_vote", "promotion_vote", "exploration", "promotion":
+		return true
+	}
+	return false
+}
+
+// Vote operation, either upvoting proposals or casting a ballot
+type Vote struct {
+	Kind      string
+	Source    string
+	Period    int64
+	Proposals []string
+	Ballot    string
+}
+
+// GetCurrentPeriod at the block with `blockID`, a hash, level or `head`.
+// Schema defined here:
+// https://tezos.gitlab.io/alphanet/api/rpc.html#get-block-id-votes-current-period
+func GetCurrentPeriod(blockID string) (*VotingPeriod, error) {
+	var data map[string]interface{}
+	if err := getVotes("current_period", blockID, &data); err != nil {
+		return nil, err
+	}
+	return parseVotingPeriod(data), nil
+}
+
+func parseVotingPeriod(data map[string]interface{}) *VotingPeriod {
+	period := VotingPeriod{Remaining: -1}
+	if info, ok := data["voting_period"].(map[string]interface{}); ok {
+		period.Index = toInt64(info["index"])
+		period.Kind, _ = info["kind"].(string)
+		period.StartPosition = toInt64(info["start_position"])
+	}
+	period.Position = toInt64(data["position"])
+	if _, ok := data["remaining"]; ok {
+		period.Remaining = toInt64(data["remaining"])
+	}
+	return &period
+}
+
+// GetProposals and the voting power behind each, at the block with `blockID`
+func GetProposals(blockID string) (map[string]int64, error) {
+	var data [][]interface{}
+	if err := getVotes("proposals", blockID, &data); err != nil {
+		return nil, err
+	}
+	proposals := map[string]int64{}
+	for _, p := range data {
+		if len(p) == 2 {
+			proposals[p[0].(string)] = toInt64(p[1])
+		}
+	}
+	return proposals, nil
+}
+
+// VotingInfo of a baker's votes this period
+type VotingInfo struct {
+	Ballot    string
+	Proposals []string
+}
+
+// Voted reports whether the baker has cast a ballot or upvoted a proposal
+// this period
+func (v *VotingInfo) Voted() bool {
+	return len(v.Ballot) > 0 || len(v.Proposals) > 0
+}
+
+// GetVotingInfo of `pkh` at the block with `blockID`.  Nodes before Jakarta
+// don't serve it, and return errNotFound
+func GetVotingInfo(pkh string, blockID string) (*VotingInfo, error) {
+	var data map[string]interface{}
+	err := getRights("voting_info", fmt.Sprintf("chains/main/blocks/%v/context/delegates/%v/voting_info", blockID, pkh), &data)
+	if err != nil {
+		return nil, err
+	}
+	info := VotingInfo{}
+	info.Ballot, _ = data["current_ballot"].(string)
+	if proposals, ok := data["current_proposals"].([]interface{}); ok {
+		for _, p := range proposals {
+			if proposal, ok := p.(string); ok {
+				info.Proposals = append(info.Proposals, proposal)
+			}
+		}
+	}
+	return &info, nil
+}
+
+// GetBallotList of each baker's ballot this period, at the block with
+// `blockID`
+func GetBallotList(blockID string) (map[string]string, error) {
+	var data []map[string]interface{}
+	if err := getVotes("ballot_list", blockID, &data); err != nil {
+		return nil, err
+	}
+	ballots := map[string]string{}
+	for _, b := range data {
+		pkh, _ := b["pkh"].(string)
+		ballots[pkh], _ = b["ballot"].(string)
+	}
+	return ballots, nil
+}
+
+// GetListings of bakers allowed to vote this period and their voting power,
+// at the block with `blockID`
+func GetListings(blockID string) (map[string]int64, error) {
+	var data []map[string]interface{}
+	if err := getVotes("listings", blockID, &data); err != nil {
+		return nil, err
+	}
+	listings := map[string]int64{}
+	for _, l := range data {
+		pkh, _ := l["pkh"].(string)
+		if power, ok := l["voting_power"]; ok {
+			listings[pkh] = toInt64(power)
+		} else {
+			listings[pkh] = toInt64(l["rolls"])
+		}
+	}
+	return listings, nil
+}
+
+// Votes cast in the block
+func (block *Block) Votes() []Vote {
+	votes := []Vote{}
+
+	operations := block.data["operations"].([]interface{})
+	for _, outer := range operations {
+		for _, inner := range outer.([]interface{}) {
+			operation := inner.(map[string]interface{})
+			contents := operation["contents"].([]interface{})
+			for _, c := range contents {
+				typedContents := c.(map[string]interface{})
+				if typedContents["kind"] != "proposals" && typedContents["kind"] != "ballot" {
+					continue
+				}
+				vote := Vote{
+					Kind:   typedContents["kind"].(string),
+					Period: toInt64(typedContents["period"]),
+				}
+				vote.Source, _ = typedContents["source"].(string)
+				vote.Ballot, _ = typedContents["ballot"].(string)
+				if proposal, ok := typedContents["proposal"].(string); ok {
+					vote.Proposals = []string{proposal}
+				}
+				if proposals, ok := typedContents["proposals"].([]interface{}); ok {
+					for _, p := range proposals {
+						vote.Proposals = append(vote.Proposals, p.(string))
+					}
+				}
+				votes = append(votes, vote)
+			}
+		}
+	}
+	return votes
+}
+
+// getVotes from the `endpoint` votes RPC into `data`
+func getVotes(endpoint string, blockID string, data interface{}) error {
+	resp, err := get(http.DefaultClient, endpoint, fmt.Sprintf("chains/main/blocks/%v/votes/%v", blockID, endpoint))
+	if err != nil {
+		logger.WithField("endpoint", endpoint).WithError(err).Error("Unable to query endpoint")
+		return err
+	}
+
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		logger.WithField("endpoint", endpoint).WithError(err).Error("Unable to read response")
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Unexpected status %v from %v: %v", resp.StatusCode, endpoint, string(body))
+	}
+
+	err = json.Unmarshal(body, data)
+	if err != nil {
+		logger.WithField("endpoint", endpoint).WithError(err).Error("Unable to parse json payload")
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package tzrpc
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestVotingPeriod(t *testing.T) {
+	var data map[string]interface{}
+	check(json.Unmarshal([]byte(`{
+		"voting_period": {"index": 42, "kind": "exploration", "start_position": 1433600},
+		"position": 20000,
+		"remaining": 20959
+	}`), &data))
+
+	period := parseVotingPeriod(data)
+	if period.Index != 42 || period.Kind != "exploration" || period.Remaining != 20959 || !period.Voting() {
+		t.Error("Incorrect voting period", period)
+	}
+}
+
+func TestVotes(t *testing.T) {
+	block, err := parseBlock([]byte(`{
+		"hash": "BLx",
+		"header": {"level": 1453601},
+		"operations": [[], [{"contents": [
+			{"kind": "proposals", "source": "tz1a", "period": 41, "proposals": ["PtA", "PtB"]},
+			{"kind": "ballot", "source": "tz1b", "period": 42, "proposal": "PtA", "ballot": "yay"}
+		]}], [], []]
+	}`))
+	check(err)
+
+	votes := block.Votes()
+	if len(votes) != 2 {
+		t.Fatal("Expected 2 votes but found", len(votes))
+	}
+	if votes[0].Kind != "proposals" || votes[0].Source != "tz1a" || len(votes[0].Proposals) != 2 {
+		t.Error("Incorrect proposals", votes[0])
+	}
+	if votes[1].Kind != "ballot" || votes[1].Ballot != "yay" || votes[1].Period != 42 || votes[1].Proposals[0] != "PtA" {
+		t.Error("Incorrect ballot", votes[1])
+	}
+}